@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/rs/zerolog"
+
+	"github.com/Karzoug/meower-timeline-service/internal/delivery/kafka"
+)
+
+// dlq-redrive moves failed change tasks from the dead-letter topic back to the timelines topic.
+// It is configured with the same CONSUMER_KAFKA_ environment variables as the service.
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer cancel()
+
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	cfg, err := env.ParseAsWithOptions[kafka.RedriveConfig](env.Options{
+		Prefix: "CONSUMER_KAFKA_",
+	})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("failed to parse config")
+		os.Exit(1)
+	}
+
+	n, err := kafka.Redrive(ctx, cfg, logger)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Int("count", n).
+			Msg("error re-driving messages")
+		os.Exit(1)
+	}
+
+	logger.Info().
+		Int("count", n).
+		Msg("re-driven messages")
+}
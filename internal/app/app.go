@@ -19,8 +19,13 @@ import (
 	timelineHandler "github.com/Karzoug/meower-timeline-service/internal/delivery/grpc/handler/timeline"
 	grpcServer "github.com/Karzoug/meower-timeline-service/internal/delivery/grpc/server"
 	"github.com/Karzoug/meower-timeline-service/internal/delivery/kafka"
+	memoryBroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker/memory"
+	broker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker/redis"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/client/grpc/post"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/client/grpc/relation"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/repo/cache"
+	memoryRepo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/memory"
 	repo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/redis"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/service"
 	"github.com/Karzoug/meower-timeline-service/pkg/buildinfo"
@@ -43,6 +48,13 @@ func Run(ctx context.Context, logger zerolog.Logger) error {
 		return err
 	}
 	zerolog.SetGlobalLevel(cfg.LogLevel)
+	if err := entity.SetPostEncoding(cfg.PostEncoding); err != nil {
+		return err
+	}
+	if !entity.PostEncodingKeepsRepostID() {
+		logger.Warn().
+			Msg("post encoding 1 can't keep repost ids: reposts are ordered by the original posts until POST_ENCODING=2")
+	}
 
 	logger.Info().
 		Int("GOMAXPROCS", runtime.GOMAXPROCS(0)).
@@ -74,12 +86,6 @@ func Run(ctx context.Context, logger zerolog.Logger) error {
 	}
 	defer doClose(shutdownMeter, logger)
 
-	redisDB, err := redis.NewDB(ctxInit, cfg.Redis)
-	if err != nil {
-		return err
-	}
-	defer doClose(redisDB.Close, logger)
-
 	// set up post microservice grpc client
 	postClient, err := post.NewServiceClient(cfg.PostService)
 	if err != nil {
@@ -92,10 +98,49 @@ func Run(ctx context.Context, logger zerolog.Logger) error {
 		return fmt.Errorf("could not connect to relation microservice: %w", err)
 	}
 
-	// set up service
-	ts, err := service.NewTimelineService(cfg.Service, repo.NewTimelineRepo(redisDB, logger), relationClient, postClient, ctx.Done(), tracer, logger)
-	if err != nil {
-		return err
+	// set up timeline repo, events broker and service
+	var (
+		ts service.TimelineService
+		// runs in background while the service works
+		runners []func(context.Context) error
+	)
+	switch cfg.Storage {
+	case config.StorageMemory:
+		logger.Warn().
+			Msg("timelines are stored in memory")
+
+		memBroker := memoryBroker.NewBroker(cfg.Watch)
+		runners = append(runners, memBroker.Run)
+
+		ts, err = service.NewTimelineService(cfg.Service, memoryRepo.NewTimelineRepo(), relationClient, postClient, memBroker, ctx.Done(), tracer, logger)
+		if err != nil {
+			return err
+		}
+	case config.StorageRedis:
+		redisDB, err := redis.NewDB(ctxInit, cfg.Redis)
+		if err != nil {
+			return err
+		}
+		defer doClose(redisDB.Close, logger)
+
+		redisBroker := broker.NewBroker(cfg.Watch, cfg.Repo.KeyPrefix, redisDB, logger)
+		runners = append(runners, redisBroker.Run)
+
+		redisRepo, err := repo.NewTimelineRepo(cfg.Repo, redisDB, logger)
+		if err != nil {
+			return err
+		}
+
+		// in-process cache of the first pages is invalidated by the mutations made by any instance
+		timelineRepo := cache.NewRepo(cfg.Cache, redisRepo, cache.NewRedisInvalidations(cfg.Repo.KeyPrefix, redisDB, logger), logger)
+		runners = append(runners, timelineRepo.Run)
+
+		ts, err = service.NewTimelineService(cfg.Service, timelineRepo, relationClient, postClient, redisBroker, ctx.Done(), tracer, logger)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown storage: %s", cfg.Storage)
 	}
 
 	// set up kafka producer
@@ -124,6 +169,12 @@ func Run(ctx context.Context, logger zerolog.Logger) error {
 	eg.Go(func() error {
 		return kafkaConsumer.Run(ctx)
 	})
+	// run timeline events broker and cache invalidations receiver
+	for _, run := range runners {
+		eg.Go(func() error {
+			return run(ctx)
+		})
+	}
 	// run prometheus metrics http server
 	eg.Go(func() error {
 		return prom.Serve(ctx, cfg.PromHTTP, logger)
@@ -6,21 +6,43 @@ import (
 
 	grpcConfig "github.com/Karzoug/meower-timeline-service/internal/delivery/grpc/server"
 	"github.com/Karzoug/meower-timeline-service/internal/delivery/kafka"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/client/grpc"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/repo/cache"
+	repo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/redis"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/service"
 	"github.com/Karzoug/meower-timeline-service/pkg/redis"
 
 	"github.com/rs/zerolog"
 )
 
+// Storage is the kind of timelines storage.
+type Storage string
+
+const (
+	// StorageRedis keeps timelines in redis shared by all service instances.
+	StorageRedis Storage = "redis"
+	// StorageMemory keeps timelines in memory of the single service instance
+	// without redis (for local development only).
+	StorageMemory Storage = "memory"
+)
+
 type Config struct {
 	LogLevel        zerolog.Level     `env:"LOG_LEVEL" envDefault:"info"`
+	Storage         Storage           `env:"STORAGE" envDefault:"redis"`
 	GRPC            grpcConfig.Config `envPrefix:"GRPC_"`
 	PromHTTP        prom.ServerConfig `envPrefix:"PROM_"`
 	OTLP            otlp.Config       `envPrefix:"OTLP_"`
 	ConsumerKafka   kafka.Config      `envPrefix:"CONSUMER_KAFKA_"`
 	Service         service.Config    `envPrefix:"SERVICE_"`
 	Redis           redis.Config      `envPrefix:"REDIS_"`
+	Repo            repo.Config       `envPrefix:"REPO_"`
+	Cache           cache.Config      `envPrefix:"CACHE_"`
+	Watch           broker.Config     `envPrefix:"WATCH_"`
 	PostService     grpc.Config       `envPrefix:"POST_SERVICE_"`
 	RelationService grpc.Config       `envPrefix:"RELATION_SERVICE_"`
+	// PostEncoding is the format the timeline records are written with (see entity.PostEncoding):
+	// version 2 should be enabled only when all instances of the service read it.
+	PostEncoding entity.PostEncoding `env:"POST_ENCODING" envDefault:"1"`
 }
@@ -1,16 +1,23 @@
 package converter
 
 import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
 	gen "github.com/Karzoug/meower-timeline-service/pkg/proto/grpc/timeline/v1"
 )
 
 func ToProtoPost(p entity.Post) *gen.Post {
-	return &gen.Post{
+	res := &gen.Post{
 		AuthorId: p.AuthorID.String(),
 		PostId:   p.PostID.String(),
 		IsRepost: p.IsRepost,
 	}
+	if p.IsRepost {
+		res.ReposterId = p.RepostedBy.String()
+		res.RepostId = p.SortID().String()
+	}
+	return res
 }
 
 func ToProtoPosts(pp []entity.Post) []*gen.Post {
@@ -20,3 +27,35 @@ func ToProtoPosts(pp []entity.Post) []*gen.Post {
 	}
 	return res
 }
+
+func ToProtoFullPost(p entity.FullPost) *gen.Post {
+	res := ToProtoPost(p.Post)
+	res.Text = p.Text
+	if !p.UpdatedTime.IsZero() {
+		res.UpdatedTime = timestamppb.New(p.UpdatedTime)
+	}
+	return res
+}
+
+func ToProtoFullPosts(pp []entity.FullPost) []*gen.Post {
+	res := make([]*gen.Post, len(pp))
+	for i := range pp {
+		res[i] = ToProtoFullPost(pp[i])
+	}
+	return res
+}
+
+func ToProtoTimelineEvent(e entity.TimelineEvent) *gen.WatchTimelineResponse {
+	var eventType gen.TimelineEventType
+	switch e.Type {
+	case entity.EventTypePostInserted:
+		eventType = gen.TimelineEventType_TIMELINE_EVENT_TYPE_POST_INSERTED
+	case entity.EventTypePostDeleted:
+		eventType = gen.TimelineEventType_TIMELINE_EVENT_TYPE_POST_DELETED
+	}
+
+	return &gen.WatchTimelineResponse{
+		Type: eventType,
+		Post: ToProtoPost(e.Post),
+	}
+}
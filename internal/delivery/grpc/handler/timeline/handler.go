@@ -39,15 +39,103 @@ func (h handlers) ListTimeline(ctx context.Context, req *gen.ListTimelineRequest
 		return nil, status.Error(codes.InvalidArgument, "invalid user id: "+req.Parent)
 	}
 
-	posts, err := h.timelineService.GetTimeline(ctx, auth.UserIDFromContext(ctx), userID, service.PaginationOptions{
-		Offset: int(req.PageOffset),
+	pgn := service.PaginationOptions{
+		Token:  req.PageToken,
+		Offset: int(req.PageOffset), //nolint:staticcheck // deprecated fallback
 		Limit:  int(req.PageSize),
-	})
+	}
+
+	switch req.View {
+	case gen.PostView_POST_VIEW_UNSPECIFIED, gen.PostView_POST_VIEW_BASIC:
+		posts, nextPageToken, err := h.timelineService.GetTimeline(ctx, auth.UserIDFromContext(ctx), userID, pgn)
+		if err != nil {
+			return nil, err
+		}
+
+		return &gen.ListTimelineResponse{
+			Posts:         converter.ToProtoPosts(posts),
+			NextPageToken: nextPageToken,
+		}, nil
+	case gen.PostView_POST_VIEW_FULL:
+		posts, nextPageToken, err := h.timelineService.GetFullTimeline(ctx, auth.UserIDFromContext(ctx), userID, pgn)
+		if err != nil {
+			return nil, err
+		}
+
+		return &gen.ListTimelineResponse{
+			Posts:         converter.ToProtoFullPosts(posts),
+			NextPageToken: nextPageToken,
+		}, nil
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid view: "+req.View.String())
+	}
+}
+
+func (h handlers) ListNewTimelinePosts(ctx context.Context, req *gen.ListNewTimelinePostsRequest) (*gen.ListNewTimelinePostsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	userID, err := xid.FromString(req.Parent)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id: "+req.Parent)
+	}
+
+	// the client that has seen no posts yet requests all of them as new
+	sinceID := xid.NilID()
+	if req.SincePostId != "" {
+		sinceID, err = xid.FromString(req.SincePostId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid since post id: "+req.SincePostId)
+		}
+	}
+
+	posts, count, err := h.timelineService.GetNewTimelinePosts(ctx,
+		auth.UserIDFromContext(ctx),
+		userID,
+		sinceID,
+		int(req.PageSize),
+		req.CountOnly,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &gen.ListTimelineResponse{
+	return &gen.ListNewTimelinePostsResponse{
 		Posts: converter.ToProtoPosts(posts),
+		Count: int32(count), //nolint:gosec
 	}, nil
 }
+
+func (h handlers) WatchTimeline(req *gen.WatchTimelineRequest, stream grpc.ServerStreamingServer[gen.WatchTimelineResponse]) error {
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	userID, err := xid.FromString(req.Parent)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid user id: "+req.Parent)
+	}
+
+	ctx := stream.Context()
+
+	watch, err := h.timelineService.WatchTimeline(ctx, auth.UserIDFromContext(ctx), userID)
+	if err != nil {
+		return err
+	}
+	defer watch.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watch.Events():
+			if !ok {
+				return watch.Err()
+			}
+			if err := stream.Send(converter.ToProtoTimelineEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -54,6 +54,13 @@ func New(cfg Config, serviceRegs []ServiceRegister, tracer trace.Tracer, logger
 			interceptor.Auth(),
 			recovery.UnaryServerInterceptor(recoveryOpts...),
 		),
+		grpc.ChainStreamInterceptor(
+			streamInterceptor(interceptor.Otel(tracer)),
+			logging.StreamServerInterceptor(interceptor.Logger(tracedLogger), loggerOpts...),
+			streamInterceptor(interceptor.Error(tracedLogger)),
+			streamInterceptor(interceptor.Auth()),
+			recovery.StreamServerInterceptor(recoveryOpts...),
+		),
 	)
 
 	if logger.GetLevel() <= zerolog.DebugLevel {
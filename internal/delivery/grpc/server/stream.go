@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// serverStream is a grpc.ServerStream with overridden context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamInterceptor adapts the unary interceptor to the streams: the interceptor
+// gets the stream context and no request, the context it passes to the handler
+// becomes the stream context and the error it returns is returned by the stream.
+func streamInterceptor(unary grpc.UnaryServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		_, err := unary(ss.Context(), nil,
+			&grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod},
+			func(ctx context.Context, _ any) (any, error) {
+				return nil, handler(srv, serverStream{
+					ServerStream: ss,
+					ctx:          ctx,
+				})
+			})
+		return err
+	}
+}
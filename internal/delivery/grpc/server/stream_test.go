@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Karzoug/meower-common-go/auth"
+	"github.com/Karzoug/meower-common-go/grpc/interceptor"
+	"github.com/Karzoug/meower-common-go/ucerr"
+)
+
+func Test_streamInterceptor_Error(t *testing.T) {
+	si := streamInterceptor(interceptor.Error(zerolog.Nop()))
+	info := &grpc.StreamServerInfo{FullMethod: "/timeline.v1.TimelineService/WatchTimeline"}
+	ss := serverStream{ctx: context.TODO()}
+
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "no error", err: nil, code: codes.OK},
+		{name: "service error", err: ucerr.NewError(nil, "too many watchers", codes.ResourceExhausted), code: codes.ResourceExhausted},
+		{name: "status error", err: status.Error(codes.InvalidArgument, "invalid user id"), code: codes.InvalidArgument},
+		{name: "unknown error", err: errors.New("broken"), code: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := si(nil, ss, info, func(any, grpc.ServerStream) error {
+				return tt.err
+			})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func Test_streamInterceptor_Auth(t *testing.T) {
+	si := streamInterceptor(interceptor.Auth())
+	info := &grpc.StreamServerInfo{FullMethod: "/timeline.v1.TimelineService/WatchTimeline"}
+
+	userID := xid.New()
+	ss := serverStream{ctx: metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-user-id", userID.String()))}
+
+	var got xid.ID
+	require.NoError(t, si(nil, ss, info, func(_ any, ss grpc.ServerStream) error {
+		got = auth.UserIDFromContext(ss.Context())
+		return nil
+	}))
+	assert.Equal(t, userID, got)
+
+	ss = serverStream{ctx: metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-user-id", "invalid"))}
+	err := si(nil, ss, info, func(any, grpc.ServerStream) error {
+		t.Fatal("handler is called")
+		return nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
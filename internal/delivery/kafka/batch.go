@@ -0,0 +1,241 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/rs/xid"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/proto"
+
+	pkfk "github.com/Karzoug/meower-common-go/kafka"
+	"github.com/Karzoug/meower-common-go/trace/otlp"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	timelineApi "github.com/Karzoug/meower-timeline-service/pkg/proto/kafka/timeline/v1"
+)
+
+// runBatches reads messages by batches of up to batchSize messages or batchTimeout,
+// applies them and stores offsets after the whole batch.
+func (c consumer) runBatches(ctx context.Context, offsets *offsetTracker) error {
+	for {
+		batch, err := c.readBatch(ctx)
+		if err != nil {
+			return err
+		}
+		// the messages of partitions revoked while the batch was read are redelivered to the new owner
+		batch = slices.DeleteFunc(batch, func(msg *kafka.Message) bool {
+			return !offsets.isAssigned(msg.TopicPartition)
+		})
+		if len(batch) == 0 {
+			if ctx.Err() != nil {
+				return nil
+			}
+			continue
+		}
+
+		if err := c.handleBatch(ctx, batch); err != nil {
+			// not store offsets, return from consumer with error
+			return err
+		}
+
+		c.commitBatch(batch)
+	}
+}
+
+// readBatch reads messages until the batch is full or the batch timeout
+// since the first message is over.
+func (c consumer) readBatch(ctx context.Context) ([]*kafka.Message, error) {
+	var (
+		batch    = make([]*kafka.Message, 0, c.batchSize)
+		deadline time.Time
+	)
+	for len(batch) < c.batchSize {
+		select {
+		case <-ctx.Done():
+			return batch, nil
+		default:
+		}
+
+		timeout := readTimeout
+		if len(batch) > 0 {
+			timeout = min(timeout, time.Until(deadline))
+			if timeout <= 0 {
+				break
+			}
+		}
+
+		msg, err := c.readMessage(timeout)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			continue
+		}
+
+		if len(batch) == 0 {
+			deadline = time.Now().Add(c.batchTimeout)
+		}
+		batch = append(batch, msg)
+	}
+
+	return batch, nil
+}
+
+// handleBatch applies post insert tasks of the batch at once and other tasks one by one.
+// Tasks are applied in the read order: the collected inserts are pushed before any other task,
+// since it may refer to the same timeline, post or author (e.g. the delete of the inserted post
+// targeted at another follower).
+func (c consumer) handleBatch(ctx context.Context, batch []*kafka.Message) error {
+	var (
+		posts = make(map[xid.ID][]entity.Post)
+		msgs  = make([]*kafka.Message, 0, len(batch))
+	)
+	flush := func() error {
+		if len(msgs) == 0 {
+			return nil
+		}
+		err := c.pushPosts(ctx, posts, msgs)
+		clear(posts)
+		msgs = msgs[:0]
+		return err
+	}
+
+	for _, msg := range batch {
+		if targetUserID, post, ok := parsePostInsert(msg); ok {
+			posts[targetUserID] = append(posts[targetUserID], post)
+			msgs = append(msgs, msg)
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return flush()
+}
+
+// pushPosts pushes posts to timelines of several users at once,
+// if it fails, the messages of posts are handled one by one.
+func (c consumer) pushPosts(ctx context.Context, posts map[xid.ID][]entity.Post, msgs []*kafka.Message) error {
+	ctx = otlp.InjectTracing(ctx, c.tracer)
+	ctx, span := c.tracer.Start(ctx, preffixSpanName+"postInsertBatch")
+	defer span.End()
+
+	logger := c.logger.With().
+		Int("batch size", len(msgs)).
+		Ctx(ctx).
+		Logger()
+
+	operation := func() error {
+		ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+		defer cancel()
+
+		return c.timelineService.PushTimelinePosts(ctx, posts)
+	}
+
+	err := backoff.Retry(operation,
+		backoff.WithContext(
+			backoff.NewExponentialBackOff(
+				backoff.WithMaxElapsedTime(maxRetryTimeoutBeforeExit),
+			),
+			ctx,
+		),
+	)
+	if err == nil {
+		logger.Info().Msg("processed batch of post inserts")
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "all batch retries failed")
+	logger.Warn().
+		Err(err).
+		Msg("push batch of posts failed, fallback to one by one")
+
+	for _, msg := range msgs {
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitBatch stores and commits offsets of the processed batch.
+func (c consumer) commitBatch(batch []*kafka.Message) {
+	positions := make(map[partition]kafka.TopicPartition)
+	for _, msg := range batch {
+		tp := msg.TopicPartition
+		tp.Offset++
+		positions[partition{topic: *tp.Topic, id: tp.Partition}] = tp
+	}
+
+	tps := make([]kafka.TopicPartition, 0, len(positions))
+	for _, tp := range positions {
+		tps = append(tps, tp)
+	}
+
+	if _, err := c.c.StoreOffsets(tps); err != nil {
+		c.logger.Error().
+			Err(err).
+			Msg("failed to store offsets after batch")
+		return
+	}
+
+	if _, err := c.c.Commit(); err != nil {
+		var kafkaErr kafka.Error
+		if errors.As(err, &kafkaErr) && kafkaErr.Code() == kafka.ErrNoOffset {
+			return
+		}
+		c.logger.Error().
+			Err(err).
+			Msg("failed to commit offsets after batch")
+	}
+}
+
+// parsePostInsert returns target user and post of the post insert task,
+// ok is false if the message is not a valid post insert task.
+func parsePostInsert(msg *kafka.Message) (targetUserID xid.ID, post entity.Post, ok bool) {
+	eventType, found := lookupHeaderValue(msg.Headers, pkfk.MessageTypeHeaderKey)
+	if !found || string(eventType) != changeTaskEventFngpnt {
+		return xid.NilID(), entity.Post{}, false
+	}
+
+	event := &timelineApi.ChangeTaskEvent{}
+	if err := proto.Unmarshal(msg.Value, event); err != nil {
+		return xid.NilID(), entity.Post{}, false
+	}
+	if event.ChangeType != timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_INSERT {
+		return xid.NilID(), entity.Post{}, false
+	}
+
+	targetUserID, err := xid.FromString(event.TargetUserId)
+	if err != nil {
+		return xid.NilID(), entity.Post{}, false
+	}
+	userID, err := xid.FromString(event.UserId)
+	if err != nil {
+		return xid.NilID(), entity.Post{}, false
+	}
+	postID, err := xid.FromString(event.PostId)
+	if err != nil {
+		return xid.NilID(), entity.Post{}, false
+	}
+
+	return targetUserID, entity.Post{
+		AuthorID: userID,
+		PostID:   postID,
+	}, true
+}
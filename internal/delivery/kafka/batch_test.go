@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+
+	pkfk "github.com/Karzoug/meower-common-go/kafka"
+
+	tlbroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	memoryBroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker/memory"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	memoryRepo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/memory"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/service"
+	timelineApi "github.com/Karzoug/meower-timeline-service/pkg/proto/kafka/timeline/v1"
+)
+
+type fakeRelationService struct {
+	followings map[xid.ID][]xid.ID
+}
+
+func (s fakeRelationService) ListFollowerIDs(_ context.Context, userID xid.ID) ([]xid.ID, error) {
+	var res []xid.ID
+	for followerID, followings := range s.followings {
+		if slices.Contains(followings, userID) {
+			res = append(res, followerID)
+		}
+	}
+	return res, nil
+}
+
+func (s fakeRelationService) ListNotMutedFollowingIDs(_ context.Context, userID xid.ID) ([]xid.ID, error) {
+	return slices.Clone(s.followings[userID]), nil
+}
+
+type fakePostService struct {
+	posts []entity.Post // ordered from newest to oldest
+}
+
+func (s fakePostService) ListPostIDsByUserIDs(_ context.Context, _ xid.ID, userIDs []xid.ID, limit int) ([]entity.Post, error) {
+	res := make([]entity.Post, 0)
+	for i := range s.posts {
+		if len(res) < limit && slices.Contains(userIDs, s.posts[i].AuthorID) {
+			res = append(res, s.posts[i])
+		}
+	}
+	return res, nil
+}
+
+func (s fakePostService) BatchGetPosts(context.Context, xid.ID, []xid.ID) ([]entity.PostContent, error) {
+	return nil, nil
+}
+
+func newTestConsumer(t *testing.T, ts service.TimelineService) consumer {
+	t.Helper()
+
+	return consumer{
+		workers:         1,
+		batchSize:       16,
+		timelineService: ts,
+		tracer:          noop.NewTracerProvider().Tracer(""),
+		logger:          zerolog.Nop(),
+	}
+}
+
+func changeTaskMessage(t *testing.T, key xid.ID, event *timelineApi.ChangeTaskEvent) *kafka.Message {
+	t.Helper()
+
+	value, err := proto.Marshal(event)
+	require.NoError(t, err)
+
+	topic := timelineTopic
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic},
+		Key:            []byte(key.String()),
+		Value:          value,
+		Headers: []kafka.Header{{
+			Key:   pkfk.MessageTypeHeaderKey,
+			Value: []byte(changeTaskEventFngpnt),
+		}},
+	}
+}
+
+func TestConsumer_HandleBatch(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID, otherID := xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: otherID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {authorID, otherID},
+	}}
+
+	ts := newTestService(t, relations, fakePostService{posts: []entity.Post{post1}})
+
+	// build the timeline, so posts are pushed to it
+	posts, _, err := ts.GetTimeline(ctx, userID, userID, service.PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+
+	relations.followings[userID] = []xid.ID{otherID}
+
+	// the tasks are keyed by the author, but both change the timeline of the user
+	batch := []*kafka.Message{
+		changeTaskMessage(t, authorID, &timelineApi.ChangeTaskEvent{
+			ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_INSERT,
+			UserId:       authorID.String(),
+			TargetUserId: userID.String(),
+			PostId:       post2.PostID.String(),
+		}),
+		changeTaskMessage(t, authorID, &timelineApi.ChangeTaskEvent{
+			ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNSUBSCRIBE,
+			UserId:       userID.String(),
+			TargetUserId: authorID.String(),
+		}),
+	}
+
+	c := newTestConsumer(t, ts)
+	require.NoError(t, c.handleBatch(ctx, batch))
+
+	posts, _, err = ts.GetTimeline(ctx, userID, userID, service.PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	for i := range posts {
+		assert.NotEqual(t, authorID, posts[i].AuthorID)
+	}
+	assert.Len(t, posts, 1)
+}
+
+func TestConsumer_HandleBatch_DeleteOfInsertedPost(t *testing.T) {
+	ctx := context.TODO()
+
+	follower1, follower2, authorID := xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		follower1: {authorID},
+		follower2: {authorID},
+	}}
+	repo := memoryRepo.NewTimelineRepo()
+
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	ts, err := service.NewTimelineService(service.Config{
+		Limit:             100,
+		TTL:               time.Hour,
+		TombstoneTTL:      time.Hour,
+		BuildTimeout:      time.Second,
+		UserTimelineLimit: 10,
+		UserTimelineTTL:   time.Hour,
+		CelebrityCheckTTL: time.Hour,
+	},
+		repo,
+		relations,
+		fakePostService{posts: []entity.Post{post1}},
+		memoryBroker.NewBroker(tlbroker.Config{BufferSize: 16, MaxPerUser: 1}),
+		closeChan,
+		noop.NewTracerProvider().Tracer(""),
+		zerolog.Nop(),
+	)
+	require.NoError(t, err)
+
+	// build the timelines and the recent posts list of the author
+	for _, userID := range []xid.ID{follower1, follower2} {
+		_, _, err = ts.GetTimeline(ctx, userID, userID, service.PaginationOptions{Limit: 10})
+		require.NoError(t, err)
+	}
+
+	// the post is deleted while its insert tasks for other followers are in the same batch
+	batch := []*kafka.Message{
+		changeTaskMessage(t, follower1, &timelineApi.ChangeTaskEvent{
+			ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_INSERT,
+			UserId:       authorID.String(),
+			TargetUserId: follower1.String(),
+			PostId:       post2.PostID.String(),
+		}),
+		changeTaskMessage(t, follower2, &timelineApi.ChangeTaskEvent{
+			ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_DELETE,
+			UserId:       authorID.String(),
+			TargetUserId: follower2.String(),
+			PostId:       post2.PostID.String(),
+		}),
+	}
+
+	c := newTestConsumer(t, ts)
+	require.NoError(t, c.handleBatch(ctx, batch))
+
+	// the insert is applied before the delete, so the deleted post is not pushed back
+	authorPosts, err := repo.UserListGet(ctx, []xid.ID{authorID}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post1}, authorPosts[authorID])
+
+	posts, _, err := ts.GetTimeline(ctx, follower1, follower1, service.PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post1}, posts)
+}
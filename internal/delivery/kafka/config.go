@@ -7,4 +7,19 @@ type Config struct {
 	GroupID string `env:"GROUP_ID,notEmpty" envDefault:"timeline-service"`
 	// CommitInterval defines how often to flush commits to Kafka
 	CommitIntervalMilliseconds int `env:"COMMIT_INTERVAL_MILLISECONDS" envDefault:"500"`
+	// Workers is the number of messages processed concurrently,
+	// messages with the same key are processed in order
+	Workers int `env:"WORKERS" envDefault:"16"`
+	// DLQTopic is a topic for messages failed after all retries,
+	// if empty the consumer exits on such message
+	DLQTopic string `env:"DLQ_TOPIC" envDefault:"timelines.dlq"`
+	// BatchSize is the maximum number of messages applied at once,
+	// values less than 2 disable batch mode
+	BatchSize int `env:"BATCH_SIZE" envDefault:"0"`
+	// BatchTimeoutMilliseconds is the maximum time to collect a batch
+	BatchTimeoutMilliseconds int `env:"BATCH_TIMEOUT_MILLISECONDS" envDefault:"100"`
+	// RevokeTimeoutMilliseconds is the maximum time to wait for the read messages of revoked
+	// partitions to be processed, the handlers still running are canceled and the rest of the messages
+	// are dropped: they are redelivered to the new owner
+	RevokeTimeoutMilliseconds int `env:"REVOKE_TIMEOUT_MILLISECONDS" envDefault:"10000"`
 }
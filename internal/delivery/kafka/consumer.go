@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 
 	pkfk "github.com/Karzoug/meower-common-go/kafka"
 	"github.com/Karzoug/meower-common-go/trace/otlp"
@@ -19,7 +21,10 @@ import (
 )
 
 const (
-	timelineTopic = "timelines"
+	timelineTopic            = "timelines"
+	workerQueueSize          = 64
+	flushTimeoutMilliseconds = 5000
+	readTimeout              = 100 * time.Millisecond
 )
 
 var (
@@ -29,6 +34,12 @@ var (
 
 type consumer struct {
 	c               *kafka.Consumer
+	dlqProducer     *kafka.Producer // nil if dead-letter topic is disabled
+	dlqTopic        string
+	workers         int
+	batchSize       int
+	batchTimeout    time.Duration
+	revokeTimeout   time.Duration
 	timelineService service.TimelineService
 	tracer          trace.Tracer
 	logger          zerolog.Logger
@@ -69,8 +80,25 @@ func NewConsumer(ctx context.Context, cfg Config, service service.TimelineServic
 		return consumer{}, fmt.Errorf("%s: failed to get metadata: %w", op, err)
 	}
 
+	var dlqProducer *kafka.Producer
+	if cfg.DLQTopic != "" {
+		dlqProducer, err = kafka.NewProducer(&kafka.ConfigMap{
+			"bootstrap.servers": cfg.Brokers,
+			"acks":              "all",
+		})
+		if err != nil {
+			return consumer{}, fmt.Errorf("%s: failed to create dead-letter producer: %w", op, err)
+		}
+	}
+
 	return consumer{
 		c:               c,
+		dlqProducer:     dlqProducer,
+		dlqTopic:        cfg.DLQTopic,
+		workers:         max(cfg.Workers, 1),
+		batchSize:       cfg.BatchSize,
+		batchTimeout:    time.Duration(cfg.BatchTimeoutMilliseconds) * time.Millisecond,
+		revokeTimeout:   time.Duration(cfg.RevokeTimeoutMilliseconds) * time.Millisecond,
 		timelineService: service,
 		tracer:          tracer,
 		logger:          tracedLogger,
@@ -85,72 +113,187 @@ func (c consumer) Run(ctx context.Context) (err error) {
 			err = errors.Join(err,
 				fmt.Errorf("%s: failed to close consumer: %w", op, defErr))
 		}
+		if c.dlqProducer != nil {
+			c.dlqProducer.Flush(flushTimeoutMilliseconds)
+			c.dlqProducer.Close()
+		}
 	}()
 
-	if err := c.c.SubscribeTopics(topics, nil); err != nil {
+	offsets := newOffsetTracker()
+
+	if err := c.c.SubscribeTopics(topics, c.rebalanceCallback(ctx, offsets)); err != nil {
 		return err
 	}
 
-	run := true
-	for run {
+	if c.batchSize > 1 {
+		if err := c.runBatches(ctx, offsets); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+
+	// messages with the same key are processed by the same worker in the read order
+	queues := make([]chan *kafka.Message, c.workers)
+	for i := range queues {
+		queues[i] = make(chan *kafka.Message, workerQueueSize)
+		eg.Go(func() error {
+			return c.work(egCtx, queues[i], offsets)
+		})
+	}
+
+	eg.Go(func() error {
+		defer func() {
+			for i := range queues {
+				close(queues[i])
+			}
+		}()
+		return c.read(egCtx, queues, offsets)
+	})
+
+	if err := eg.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c consumer) read(ctx context.Context, queues []chan *kafka.Message, offsets *offsetTracker) error {
+	for {
 		select {
 		case <-ctx.Done():
-			run = false
+			return nil
 		default:
-			msg, err := c.c.ReadMessage(100 * time.Millisecond)
-			if err != nil {
-				var kafkaErr kafka.Error
-				if errors.As(err, &kafkaErr) {
-					if kafkaErr.IsFatal() {
-						return fmt.Errorf("%s: fatal error while read message: %w", op, err)
-					}
-					if !kafkaErr.IsTimeout() {
-						c.logger.Error().
-							Err(err).
-							Msg("failed to read message")
-					}
-				}
-				continue
-			}
+		}
 
-			if len(msg.Headers) == 0 {
-				c.storeOffset(msg)
-				continue
+		msg, err := c.readMessage(readTimeout)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			continue
+		}
+
+		offsets.add(msg.TopicPartition)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case queues[queueIndex(msg.Key, len(queues))] <- msg:
+		}
+	}
+}
+
+// readMessage reads the next message, it returns nil message
+// on timeout or not fatal error and error only if it is fatal.
+func (c consumer) readMessage(timeout time.Duration) (*kafka.Message, error) {
+	msg, err := c.c.ReadMessage(timeout)
+	if err != nil {
+		var kafkaErr kafka.Error
+		if errors.As(err, &kafkaErr) {
+			if kafkaErr.IsFatal() {
+				return nil, fmt.Errorf("fatal error while read message: %w", err)
 			}
-			eventType, ok := lookupHeaderValue(msg.Headers, pkfk.MessageTypeHeaderKey)
+			if !kafkaErr.IsTimeout() {
+				c.logger.Error().
+					Err(err).
+					Msg("failed to read message")
+			}
+		}
+		return nil, nil
+	}
+
+	return msg, nil
+}
+
+func (c consumer) work(ctx context.Context, queue <-chan *kafka.Message, offsets *offsetTracker) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-queue:
 			if !ok {
-				c.storeOffset(msg)
-				continue
+				return nil
 			}
-			eventTypeFngpnt := string(eventType)
-
-			ctx = otlp.InjectTracing(ctx, c.tracer)
-			hlogger := c.logger.With().
-				Str("topic", *msg.TopicPartition.Topic).
-				Str("key", string(msg.Key)).
-				Str("event fingerprint", eventTypeFngpnt).
-				Ctx(ctx).
-				Logger()
-
-			if eventTypeFngpnt == changeTaskEventFngpnt {
-				err = c.handler(ctx, msg, hlogger)
+			if !offsets.isPending(msg.TopicPartition) {
+				// the partition was revoked, the message is redelivered to its new owner
+				continue
 			}
-
+			hctx, cancel := offsets.handlerContext(ctx, msg.TopicPartition)
+			err := c.handle(hctx, msg)
+			cancel()
 			if err != nil {
-				// log, not store offset, return from consumer with error
+				if !offsets.isPending(msg.TopicPartition) {
+					// the partition was revoked while the message was processed,
+					// the message is redelivered to its new owner
+					continue
+				}
+				// not store offset, return from consumer with error
 				return err
 			}
-
-			c.storeOffset(msg)
+			c.complete(offsets, msg)
 		}
 	}
+}
+
+// handle processes the message and sends it to the dead-letter topic on failure,
+// it returns error only if the message is neither processed nor sent.
+func (c consumer) handle(ctx context.Context, msg *kafka.Message) error {
+	err := c.process(ctx, msg)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil || c.dlqProducer == nil {
+		return err
+	}
+
+	if dlqErr := c.deadLetter(ctx, msg, err); dlqErr != nil {
+		return errors.Join(err, dlqErr)
+	}
+	c.logger.Warn().
+		Err(err).
+		Str("topic", *msg.TopicPartition.Topic).
+		Str("key", string(msg.Key)).
+		Msg("message sent to dead-letter topic")
 
 	return nil
 }
 
-func (c consumer) storeOffset(msg *kafka.Message) {
-	_, err := c.c.StoreMessage(msg)
-	if err != nil {
+func (c consumer) process(ctx context.Context, msg *kafka.Message) error {
+	if len(msg.Headers) == 0 {
+		return nil
+	}
+	eventType, ok := lookupHeaderValue(msg.Headers, pkfk.MessageTypeHeaderKey)
+	if !ok {
+		return nil
+	}
+	eventTypeFngpnt := string(eventType)
+
+	ctx = otlp.InjectTracing(ctx, c.tracer)
+	hlogger := c.logger.With().
+		Str("topic", *msg.TopicPartition.Topic).
+		Str("key", string(msg.Key)).
+		Str("event fingerprint", eventTypeFngpnt).
+		Ctx(ctx).
+		Logger()
+
+	if eventTypeFngpnt == changeTaskEventFngpnt {
+		return c.handler(ctx, msg, hlogger)
+	}
+
+	return nil
+}
+
+// complete stores the offset of the partition if all its messages
+// up to the processed one are processed.
+func (c consumer) complete(offsets *offsetTracker, msg *kafka.Message) {
+	tp, ok := offsets.complete(msg.TopicPartition)
+	if !ok {
+		return
+	}
+
+	if _, err := c.c.StoreOffsets([]kafka.TopicPartition{tp}); err != nil {
 		c.logger.Error().
 			Err(err).
 			Str("topic", *msg.TopicPartition.Topic).
@@ -159,6 +302,52 @@ func (c consumer) storeOffset(msg *kafka.Message) {
 	}
 }
 
+// rebalanceCallback keeps the offset tracker in sync with the assigned partitions.
+// The new owner of the revoked partitions starts from their stored offsets,
+// so the read messages of them are waited for up to the revoke timeout and the rest are dropped:
+// the handlers still running are canceled and the queued messages are skipped.
+// Delivery is at-least-once: the dropped messages are processed again by the new owner,
+// though the canceled handlers may have applied them partially. Their writes already sent
+// may also land after the new owner processed the later messages of the same key,
+// so such messages may be applied out of order.
+// The callback is called from the reading goroutine, so no messages are read meanwhile.
+func (c consumer) rebalanceCallback(ctx context.Context, offsets *offsetTracker) kafka.RebalanceCb {
+	return func(kc *kafka.Consumer, event kafka.Event) error {
+		switch e := event.(type) {
+		case kafka.AssignedPartitions:
+			offsets.assign(e.Partitions)
+		case kafka.RevokedPartitions:
+			// in batch mode the read messages are processed by the reading goroutine only,
+			// offsets of the lost partitions can't be committed anyway
+			if c.batchSize <= 1 && !kc.AssignmentLost() {
+				c.drain(ctx, offsets, e.Partitions)
+			}
+			offsets.revoke(e.Partitions)
+		}
+		return nil
+	}
+}
+
+// drain waits for the read messages of the revoked partitions to be processed.
+func (c consumer) drain(ctx context.Context, offsets *offsetTracker, tps []kafka.TopicPartition) {
+	ctx, cancel := context.WithTimeout(ctx, c.revokeTimeout)
+	defer cancel()
+
+	if err := offsets.drain(ctx, tps); err != nil {
+		c.logger.Warn().
+			Err(err).
+			Int("partitions", len(tps)).
+			Msg("messages of revoked partitions are dropped")
+	}
+}
+
+// queueIndex returns index of the worker queue for the message key.
+func queueIndex(key []byte, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(n)) //nolint:gosec
+}
+
 func lookupHeaderValue(headers []kafka.Header, key string) ([]byte, bool) {
 	for _, header := range headers {
 		if header.Key == key {
@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Headers of the dead-letter messages.
+const (
+	dlqErrorHeaderKey     = "dlq-error"
+	dlqAttemptsHeaderKey  = "dlq-attempts"
+	dlqTopicHeaderKey     = "dlq-original-topic"
+	dlqPartitionHeaderKey = "dlq-original-partition"
+	dlqOffsetHeaderKey    = "dlq-original-offset"
+)
+
+// processingError is the error of the message processing after all retries.
+type processingError struct {
+	err      error
+	attempts int
+}
+
+func (e processingError) Error() string {
+	return e.err.Error()
+}
+
+func (e processingError) Unwrap() error {
+	return e.err
+}
+
+// deadLetter publishes the failed message to the dead-letter topic
+// with the error and the total number of processing attempts in headers.
+func (c consumer) deadLetter(ctx context.Context, msg *kafka.Message, procErr error) error {
+	return produce(ctx, c.dlqProducer, deadLetterMessage(msg, procErr, c.dlqTopic))
+}
+
+// deadLetterMessage returns the dead-letter message of the failed message.
+func deadLetterMessage(msg *kafka.Message, procErr error, dlqTopic string) *kafka.Message {
+	attempts := 1
+	var perr processingError
+	if errors.As(procErr, &perr) {
+		attempts = perr.attempts
+	}
+	// message may be already re-driven from the dead-letter topic
+	if v, ok := lookupHeaderValue(msg.Headers, dlqAttemptsHeaderKey); ok {
+		if prev, err := strconv.Atoi(string(v)); err == nil {
+			attempts += prev
+		}
+	}
+
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	for _, h := range msg.Headers {
+		if !isDLQHeader(h.Key) {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers,
+		kafka.Header{Key: dlqErrorHeaderKey, Value: []byte(procErr.Error())},
+		kafka.Header{Key: dlqAttemptsHeaderKey, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: dlqTopicHeaderKey, Value: []byte(*msg.TopicPartition.Topic)},
+		kafka.Header{Key: dlqPartitionHeaderKey, Value: []byte(strconv.Itoa(int(msg.TopicPartition.Partition)))},
+		kafka.Header{Key: dlqOffsetHeaderKey, Value: []byte(msg.TopicPartition.Offset.String())},
+	)
+
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &dlqTopic,
+			Partition: kafka.PartitionAny,
+		},
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// produce publishes the message and waits for its delivery.
+func produce(ctx context.Context, p *kafka.Producer, msg *kafka.Message) error {
+	deliveryChan := make(chan kafka.Event, 1)
+	if err := p.Produce(msg, deliveryChan); err != nil {
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case e := <-deliveryChan:
+		m, ok := e.(*kafka.Message)
+		if !ok {
+			return fmt.Errorf("unexpected delivery event: %v", e)
+		}
+		if m.TopicPartition.Error != nil {
+			return fmt.Errorf("failed to deliver message: %w", m.TopicPartition.Error)
+		}
+		return nil
+	}
+}
+
+func isDLQHeader(key string) bool {
+	switch key {
+	case dlqErrorHeaderKey, dlqAttemptsHeaderKey, dlqTopicHeaderKey, dlqPartitionHeaderKey, dlqOffsetHeaderKey:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkfk "github.com/Karzoug/meower-common-go/kafka"
+
+	timelineApi "github.com/Karzoug/meower-timeline-service/pkg/proto/kafka/timeline/v1"
+)
+
+func TestConsumer_Handle_PermanentError(t *testing.T) {
+	c := newTestConsumer(t, newTestService(t, fakeRelationService{}, fakePostService{}))
+
+	// invalid ids are not retried
+	msg := changeTaskMessage(t, xid.New(), &timelineApi.ChangeTaskEvent{
+		ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_INSERT,
+		UserId:       "not an id",
+		TargetUserId: xid.New().String(),
+		PostId:       xid.New().String(),
+	})
+
+	err := c.handle(context.TODO(), msg)
+	var perr processingError
+	require.ErrorAs(t, err, &perr)
+	assert.Equal(t, 1, perr.attempts)
+}
+
+func TestDeadLetterMessage_Redrive(t *testing.T) {
+	const dlqTopic = "timelines.dlq"
+
+	header := func(msg *kafka.Message, key string) string {
+		t.Helper()
+
+		var (
+			res   string
+			found int
+		)
+		for _, h := range msg.Headers {
+			if h.Key == key {
+				res = string(h.Value)
+				found++
+			}
+		}
+		require.LessOrEqual(t, found, 1, "duplicated header %s", key)
+		return res
+	}
+
+	topic := timelineTopic
+	msg := changeTaskMessage(t, xid.New(), &timelineApi.ChangeTaskEvent{})
+	msg.TopicPartition = kafka.TopicPartition{Topic: &topic, Partition: 3, Offset: 42}
+
+	dlqMsg := deadLetterMessage(msg, processingError{err: errors.New("failed"), attempts: 3}, dlqTopic)
+	assert.Equal(t, dlqTopic, *dlqMsg.TopicPartition.Topic)
+	assert.Equal(t, msg.Key, dlqMsg.Key)
+	assert.Equal(t, msg.Value, dlqMsg.Value)
+	assert.Equal(t, changeTaskEventFngpnt, header(dlqMsg, pkfk.MessageTypeHeaderKey))
+	assert.Equal(t, "failed", header(dlqMsg, dlqErrorHeaderKey))
+	assert.Equal(t, "3", header(dlqMsg, dlqAttemptsHeaderKey))
+	assert.Equal(t, timelineTopic, header(dlqMsg, dlqTopicHeaderKey))
+	assert.Equal(t, "3", header(dlqMsg, dlqPartitionHeaderKey))
+	assert.Equal(t, "42", header(dlqMsg, dlqOffsetHeaderKey))
+
+	// the re-driven message keeps only the attempts
+	redriven := redriveMessage(dlqMsg)
+	assert.Equal(t, timelineTopic, *redriven.TopicPartition.Topic)
+	assert.Equal(t, msg.Key, redriven.Key)
+	assert.Equal(t, msg.Value, redriven.Value)
+	assert.Equal(t, changeTaskEventFngpnt, header(redriven, pkfk.MessageTypeHeaderKey))
+	assert.Equal(t, "3", header(redriven, dlqAttemptsHeaderKey))
+	assert.Empty(t, header(redriven, dlqErrorHeaderKey))
+	assert.Empty(t, header(redriven, dlqOffsetHeaderKey))
+
+	// the attempts are counted through re-drives
+	redriven.TopicPartition = kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 7}
+	dlqMsg = deadLetterMessage(redriven, processingError{err: errors.New("failed again"), attempts: 2}, dlqTopic)
+	assert.Equal(t, "failed again", header(dlqMsg, dlqErrorHeaderKey))
+	assert.Equal(t, "5", header(dlqMsg, dlqAttemptsHeaderKey))
+	assert.Equal(t, "7", header(dlqMsg, dlqOffsetHeaderKey))
+}
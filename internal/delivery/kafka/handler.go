@@ -40,12 +40,18 @@ func (c consumer) handler(ctx context.Context, msg *kafka.Message, logger zerolo
 		spanMethodName += "postInsert"
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_DELETE:
 		spanMethodName += "postDelete"
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_REPOST_INSERT:
+		spanMethodName += "repostInsert"
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_DELETE:
 		spanMethodName += "userDelete"
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_SUBSCRIBE:
 		spanMethodName += "userSubscribe"
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNSUBSCRIBE:
 		spanMethodName += "userUnsubscribe"
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_MUTE:
+		spanMethodName += "userMute"
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNMUTE:
+		spanMethodName += "userUnmute"
 	default:
 		return nil
 	}
@@ -59,22 +65,37 @@ func (c consumer) handler(ctx context.Context, msg *kafka.Message, logger zerolo
 		operation = c.buildPostInsertOperation(ctx, event, logger)
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_POST_DELETE:
 		operation = c.buildPostDeleteOperation(ctx, event, logger)
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_REPOST_INSERT:
+		operation = c.buildRepostInsertOperation(ctx, event, msg.Timestamp, logger)
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_DELETE:
 		operation = c.buildUserDeleteOperation(ctx, event, logger)
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_SUBSCRIBE:
 		operation = c.buildUserSubscribeOperation(ctx, event, logger)
 	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNSUBSCRIBE:
 		operation = c.buildUserUnsubscribeOperation(ctx, event, logger)
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_MUTE:
+		operation = c.buildUserMuteOperation(ctx, event, logger)
+	case timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNMUTE:
+		operation = c.buildUserUnmuteOperation(ctx, event, logger)
 	}
 
-	if err := backoff.Retry(operation,
-		backoff.NewExponentialBackOff(
-			backoff.WithMaxElapsedTime(maxRetryTimeoutBeforeExit),
+	var attempts int
+	countedOperation := func() error {
+		attempts++
+		return operation()
+	}
+
+	if err := backoff.Retry(countedOperation,
+		backoff.WithContext(
+			backoff.NewExponentialBackOff(
+				backoff.WithMaxElapsedTime(maxRetryTimeoutBeforeExit),
+			),
+			ctx,
 		),
 	); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "all operation retries failed")
-		return err
+		return processingError{err: err, attempts: attempts}
 	}
 
 	logger.Info().Msg("processed message")
@@ -130,6 +151,75 @@ func (c consumer) buildPostInsertOperation(ctx context.Context, event *timelineA
 	}
 }
 
+func (c consumer) buildRepostInsertOperation(ctx context.Context, event *timelineApi.ChangeTaskEvent, taskTime time.Time, logger zerolog.Logger) func() error {
+	const op = "build repost insert operation"
+
+	// the repost is ordered by the task time if the producer doesn't set the repost id,
+	// it is generated once, so retries push the same record
+	repostID := xid.NewWithTime(taskTime)
+	if taskTime.IsZero() {
+		repostID = xid.New()
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+		defer cancel()
+
+		targetUserID, err := xid.FromString(event.TargetUserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid target user id: %w", op, err))
+		}
+		userID, err := xid.FromString(event.UserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid user id: %w", op, err))
+		}
+		postID, err := xid.FromString(event.PostId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid post id: %w", op, err))
+		}
+		reposterID, err := xid.FromString(event.ReposterId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid reposter id: %w", op, err))
+		}
+		if event.RepostId != "" {
+			if repostID, err = xid.FromString(event.RepostId); err != nil {
+				return backoff.Permanent(fmt.Errorf("%s: invalid repost id: %w", op, err))
+			}
+		}
+
+		if err := c.timelineService.PushTimelineRepost(ctx,
+			targetUserID,
+			entity.Post{
+				AuthorID:   userID,
+				PostID:     postID,
+				IsRepost:   true,
+				RepostedBy: reposterID,
+				RepostID:   repostID,
+			}); err != nil {
+			var serr ucerr.Error
+			if errors.As(err, &serr) {
+				logger.Warn().
+					Str("target_user_id", event.TargetUserId).
+					Str("post_id", postID.String()).
+					Str("reposter_id", event.ReposterId).
+					Err(serr.Unwrap()).
+					Msg("push repost to timeline failed")
+			} else {
+				logger.Warn().
+					Str("target_user_id", event.TargetUserId).
+					Str("post_id", postID.String()).
+					Str("reposter_id", event.ReposterId).
+					Err(err).
+					Msg("push repost to timeline failed")
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
+
 func (c consumer) buildPostDeleteOperation(ctx context.Context, event *timelineApi.ChangeTaskEvent, logger zerolog.Logger) func() error {
 	const op = "build post delete operation"
 
@@ -288,3 +378,81 @@ func (c consumer) buildUserUnsubscribeOperation(ctx context.Context, event *time
 		return nil
 	}
 }
+
+func (c consumer) buildUserMuteOperation(ctx context.Context, event *timelineApi.ChangeTaskEvent, logger zerolog.Logger) func() error {
+	const op = "build user mute operation"
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+		defer cancel()
+
+		userID, err := xid.FromString(event.UserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid user id: %w", op, err))
+		}
+		targetUserID, err := xid.FromString(event.TargetUserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid target user id: %w", op, err))
+		}
+
+		if err := c.timelineService.MuteUser(ctx, userID, targetUserID); err != nil {
+			var serr ucerr.Error
+			if errors.As(err, &serr) {
+				logger.Warn().
+					Str("user_id", event.UserId).
+					Str("target_user_id", event.TargetUserId).
+					Err(serr.Unwrap()).
+					Msg("mute user failed")
+			} else {
+				logger.Warn().
+					Str("user_id", event.UserId).
+					Str("target_user_id", event.TargetUserId).
+					Err(err).
+					Msg("mute user failed")
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+func (c consumer) buildUserUnmuteOperation(ctx context.Context, event *timelineApi.ChangeTaskEvent, logger zerolog.Logger) func() error {
+	const op = "build user unmute operation"
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(ctx, defaultOperationTimeout)
+		defer cancel()
+
+		userID, err := xid.FromString(event.UserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid user id: %w", op, err))
+		}
+		targetUserID, err := xid.FromString(event.TargetUserId)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("%s: invalid target user id: %w", op, err))
+		}
+
+		if err := c.timelineService.UnmuteUser(ctx, userID, targetUserID); err != nil {
+			var serr ucerr.Error
+			if errors.As(err, &serr) {
+				logger.Warn().
+					Str("user_id", event.UserId).
+					Str("target_user_id", event.TargetUserId).
+					Err(serr.Unwrap()).
+					Msg("unmute user failed")
+			} else {
+				logger.Warn().
+					Str("user_id", event.UserId).
+					Str("target_user_id", event.TargetUserId).
+					Err(err).
+					Msg("unmute user failed")
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	tlbroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	memoryBroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker/memory"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	memoryRepo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/memory"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/service"
+	timelineApi "github.com/Karzoug/meower-timeline-service/pkg/proto/kafka/timeline/v1"
+)
+
+func newTestService(t *testing.T, relations fakeRelationService, posts fakePostService) service.TimelineService {
+	t.Helper()
+
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	ts, err := service.NewTimelineService(service.Config{
+		Limit:             100,
+		TTL:               time.Hour,
+		TombstoneTTL:      time.Hour,
+		BuildTimeout:      time.Second,
+		UserTimelineLimit: 10,
+		UserTimelineTTL:   time.Hour,
+		CelebrityCheckTTL: time.Hour,
+	},
+		memoryRepo.NewTimelineRepo(),
+		relations,
+		posts,
+		memoryBroker.NewBroker(tlbroker.Config{BufferSize: 16, MaxPerUser: 1}),
+		closeChan,
+		noop.NewTracerProvider().Tracer(""),
+		zerolog.Nop(),
+	)
+	require.NoError(t, err)
+
+	return ts
+}
+
+func TestConsumer_Handler_MuteUnmute(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, mutedID, otherID := xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: mutedID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: otherID}
+	post3 := entity.Post{PostID: xid.New(), AuthorID: mutedID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {mutedID, otherID},
+	}}
+	ts := newTestService(t, relations, fakePostService{posts: []entity.Post{post3, post2, post1}})
+	c := newTestConsumer(t, ts)
+
+	timeline := func() []entity.Post {
+		posts, _, err := ts.GetTimeline(ctx, userID, userID, service.PaginationOptions{Limit: 10})
+		require.NoError(t, err)
+		return posts
+	}
+	require.Equal(t, []entity.Post{post3, post2, post1}, timeline())
+
+	// the muted following is not returned by relation service anymore
+	relations.followings[userID] = []xid.ID{otherID}
+	require.NoError(t, c.handler(ctx, changeTaskMessage(t, userID, &timelineApi.ChangeTaskEvent{
+		ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_MUTE,
+		UserId:       userID.String(),
+		TargetUserId: mutedID.String(),
+	}), zerolog.Nop()))
+	assert.Equal(t, []entity.Post{post2}, timeline())
+
+	relations.followings[userID] = []xid.ID{mutedID, otherID}
+	require.NoError(t, c.handler(ctx, changeTaskMessage(t, userID, &timelineApi.ChangeTaskEvent{
+		ChangeType:   timelineApi.ChangeTaskType_CHANGE_TASK_TYPE_USER_UNMUTE,
+		UserId:       userID.String(),
+		TargetUserId: mutedID.String(),
+	}), zerolog.Nop()))
+	assert.Equal(t, []entity.Post{post3, post2, post1}, timeline())
+}
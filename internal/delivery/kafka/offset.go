@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+type partition struct {
+	topic string
+	id    int32
+}
+
+// partitionOffsets holds offsets of the partition messages that are in progress.
+type partitionOffsets struct {
+	pending []kafka.Offset // in ascending order
+	done    map[kafka.Offset]struct{}
+	// ctx is canceled when the partition is revoked
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newPartitionOffsets() *partitionOffsets {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &partitionOffsets{
+		done:   make(map[kafka.Offset]struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// offsetTracker tracks offsets of messages processed concurrently, so the offset
+// of the partition is stored only when all earlier messages of it are processed.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partition]*partitionOffsets
+	completed  chan struct{} // closed and replaced when a message is completed
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[partition]*partitionOffsets),
+		completed:  make(chan struct{}),
+	}
+}
+
+// assign starts tracking of the assigned partitions from scratch.
+func (t *offsetTracker) assign(tps []kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range tps {
+		key := partition{topic: *tps[i].Topic, id: tps[i].Partition}
+		if po, ok := t.partitions[key]; ok {
+			po.cancel()
+		}
+		t.partitions[key] = newPartitionOffsets()
+	}
+}
+
+// isAssigned reports whether the partition of the message is tracked, i.e. it is not revoked.
+func (t *offsetTracker) isAssigned(tp kafka.TopicPartition) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.partitions[partition{topic: *tp.Topic, id: tp.Partition}]
+	return ok
+}
+
+// isPending reports whether the message is added and not completed yet,
+// it is false if its partition was revoked after the message was added.
+func (t *offsetTracker) isPending(tp kafka.TopicPartition) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	po, ok := t.partitions[partition{topic: *tp.Topic, id: tp.Partition}]
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(po.pending, tp.Offset)
+	return found
+}
+
+// handlerContext returns the context of the message handler derived from ctx,
+// it is canceled when the partition of the message is revoked.
+func (t *offsetTracker) handlerContext(ctx context.Context, tp kafka.TopicPartition) (context.Context, context.CancelFunc) {
+	t.mu.Lock()
+	po, ok := t.partitions[partition{topic: *tp.Topic, id: tp.Partition}]
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	if !ok {
+		// partition was revoked
+		cancel()
+		return ctx, cancel
+	}
+
+	stop := context.AfterFunc(po.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// add registers the read message as in progress,
+// messages of the partition must be added in the read order.
+func (t *offsetTracker) add(tp kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partition{topic: *tp.Topic, id: tp.Partition}
+	po, ok := t.partitions[key]
+	if !ok {
+		po = newPartitionOffsets()
+		t.partitions[key] = po
+	}
+	po.pending = append(po.pending, tp.Offset)
+}
+
+// complete marks the message as processed and returns the position of the partition
+// to store (next after the last message processed together with all earlier ones)
+// and true if the position has moved.
+func (t *offsetTracker) complete(tp kafka.TopicPartition) (kafka.TopicPartition, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	po, ok := t.partitions[partition{topic: *tp.Topic, id: tp.Partition}]
+	if !ok {
+		// partition was revoked
+		return kafka.TopicPartition{}, false
+	}
+	if _, found := slices.BinarySearch(po.pending, tp.Offset); !found {
+		// partition was revoked and assigned again
+		return kafka.TopicPartition{}, false
+	}
+	po.done[tp.Offset] = struct{}{}
+
+	close(t.completed)
+	t.completed = make(chan struct{})
+
+	var (
+		last  kafka.Offset
+		moved bool
+	)
+	for len(po.pending) > 0 {
+		if _, ok := po.done[po.pending[0]]; !ok {
+			break
+		}
+		last = po.pending[0]
+		moved = true
+		delete(po.done, last)
+		po.pending = po.pending[1:]
+	}
+	if !moved {
+		return kafka.TopicPartition{}, false
+	}
+
+	return kafka.TopicPartition{
+		Topic:     tp.Topic,
+		Partition: tp.Partition,
+		Offset:    last + 1,
+	}, true
+}
+
+// drain waits until all added messages of the partitions are completed or the context is done.
+func (t *offsetTracker) drain(ctx context.Context, tps []kafka.TopicPartition) error {
+	for {
+		t.mu.Lock()
+		var pending bool
+		for i := range tps {
+			po, ok := t.partitions[partition{topic: *tps[i].Topic, id: tps[i].Partition}]
+			if ok && len(po.pending) > 0 {
+				pending = true
+				break
+			}
+		}
+		completed := t.completed
+		t.mu.Unlock()
+
+		if !pending {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-completed:
+		}
+	}
+}
+
+// revoke forgets offsets of the revoked partitions and cancels the handlers
+// of their messages being processed, the messages are not completed anymore.
+func (t *offsetTracker) revoke(tps []kafka.TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range tps {
+		key := partition{topic: *tps[i].Topic, id: tps[i].Partition}
+		if po, ok := t.partitions[key]; ok {
+			po.cancel()
+			delete(t.partitions, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_offsetTracker_Revoke(t *testing.T) {
+	topic := timelineTopic
+	tp := func(offset int) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	tracker.assign([]kafka.TopicPartition{tp(0)})
+	for i := range 3 {
+		tracker.add(tp(i))
+	}
+
+	// the revoke waits for the read messages
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		for i := range 3 {
+			tracker.complete(tp(i))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	require.NoError(t, tracker.drain(ctx, []kafka.TopicPartition{tp(0)}))
+
+	tracker.add(tp(3))
+	tracker.revoke([]kafka.TopicPartition{tp(0)})
+	assert.False(t, tracker.isAssigned(tp(3)))
+	assert.False(t, tracker.isPending(tp(3)))
+
+	// the messages read before the partition was assigned again are not completed
+	tracker.assign([]kafka.TopicPartition{tp(0)})
+	tracker.add(tp(4))
+	_, ok := tracker.complete(tp(3))
+	assert.False(t, ok)
+
+	res, ok := tracker.complete(tp(4))
+	require.True(t, ok)
+	assert.Equal(t, kafka.Offset(5), res.Offset)
+	assert.Empty(t, tracker.partitions[partition{topic: topic}].done)
+}
+
+func Test_offsetTracker_HandlerContext(t *testing.T) {
+	topic := timelineTopic
+	tp := func(id int32, offset int) kafka.TopicPartition {
+		return kafka.TopicPartition{Topic: &topic, Partition: id, Offset: kafka.Offset(offset)}
+	}
+
+	tracker := newOffsetTracker()
+	tracker.assign([]kafka.TopicPartition{tp(0, 0), tp(1, 0)})
+	tracker.add(tp(0, 0))
+	tracker.add(tp(1, 0))
+
+	ctx0, cancel0 := tracker.handlerContext(context.TODO(), tp(0, 0))
+	defer cancel0()
+	ctx1, cancel1 := tracker.handlerContext(context.TODO(), tp(1, 0))
+	defer cancel1()
+
+	// the drain timed out: the handlers of the revoked partition are canceled
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tracker.drain(ctx, []kafka.TopicPartition{tp(0, 0)}), context.DeadlineExceeded)
+	require.NoError(t, ctx0.Err())
+
+	tracker.revoke([]kafka.TopicPartition{tp(0, 0)})
+	select {
+	case <-ctx0.Done():
+	case <-time.After(time.Second):
+		require.Fail(t, "handler of revoked partition is not canceled")
+	}
+	assert.NoError(t, ctx1.Err())
+
+	// the handler of the message read before the revoke is canceled at once
+	ctx0, cancel0 = tracker.handlerContext(context.TODO(), tp(0, 1))
+	defer cancel0()
+	assert.ErrorIs(t, ctx0.Err(), context.Canceled)
+
+	// the new assignment is not affected by the revoke
+	tracker.assign([]kafka.TopicPartition{tp(0, 0)})
+	tracker.add(tp(0, 2))
+	ctx0, cancel0 = tracker.handlerContext(context.TODO(), tp(0, 2))
+	defer cancel0()
+	assert.NoError(t, ctx0.Err())
+}
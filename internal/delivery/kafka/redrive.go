@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/rs/zerolog"
+)
+
+type RedriveConfig struct {
+	// Kafka brokers addresses separated by comma
+	Brokers string `env:"BROKERS,notEmpty"`
+	// GroupID is a kafka consumer group id of the dead-letter topic reader
+	GroupID string `env:"DLQ_GROUP_ID,notEmpty" envDefault:"timeline-service-dlq-redrive"`
+	// DLQTopic is a topic of the failed messages
+	DLQTopic string `env:"DLQ_TOPIC,notEmpty" envDefault:"timelines.dlq"`
+	// IdleTimeout is how long to wait for new messages before exit
+	IdleTimeout time.Duration `env:"DLQ_IDLE_TIMEOUT" envDefault:"10s"`
+}
+
+// Redrive moves messages from the dead-letter topic back to the timelines topic
+// until there are no new messages for the idle timeout and returns the number of moved messages.
+// The number of processing attempts is kept in the message headers.
+func Redrive(ctx context.Context, cfg RedriveConfig, logger zerolog.Logger) (n int, err error) {
+	const op = "redrive dead-letter messages"
+
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  cfg.Brokers,
+		"group.id":           cfg.GroupID,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create consumer: %w", op, err)
+	}
+	defer func() {
+		if defErr := c.Close(); defErr != nil {
+			err = errors.Join(err,
+				fmt.Errorf("%s: failed to close consumer: %w", op, defErr))
+		}
+	}()
+
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": cfg.Brokers,
+		"acks":              "all",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create producer: %w", op, err)
+	}
+	defer func() {
+		p.Flush(flushTimeoutMilliseconds)
+		p.Close()
+	}()
+
+	if err := c.SubscribeTopics([]string{cfg.DLQTopic}, nil); err != nil {
+		return 0, fmt.Errorf("%s: failed to subscribe: %w", op, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return n, nil
+		default:
+		}
+
+		msg, err := c.ReadMessage(cfg.IdleTimeout)
+		if err != nil {
+			var kafkaErr kafka.Error
+			if errors.As(err, &kafkaErr) && kafkaErr.IsTimeout() {
+				return n, nil
+			}
+			return n, fmt.Errorf("%s: failed to read message: %w", op, err)
+		}
+
+		if err := produce(ctx, p, redriveMessage(msg)); err != nil {
+			return n, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if _, err := c.CommitMessage(msg); err != nil {
+			return n, fmt.Errorf("%s: failed to commit message: %w", op, err)
+		}
+		n++
+
+		logger.Info().
+			Str("key", string(msg.Key)).
+			Msg("message re-driven")
+	}
+}
+
+// redriveMessage returns the message of the timelines topic re-driven from the dead-letter message.
+func redriveMessage(msg *kafka.Message) *kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		// keep attempts to count them through re-drives
+		if !isDLQHeader(h.Key) || h.Key == dlqAttemptsHeaderKey {
+			headers = append(headers, h)
+		}
+	}
+
+	topic := timelineTopic
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: kafka.PartitionAny,
+		},
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,9 @@
+package broker
+
+type Config struct {
+	// BufferSize is the number of events buffered for each watcher,
+	// the watcher that doesn't keep up is disconnected.
+	BufferSize int `env:"BUFFER_SIZE,notEmpty" envDefault:"64"`
+	// MaxPerUser is the maximum number of simultaneous watchers of one user timeline.
+	MaxPerUser int `env:"MAX_PER_USER,notEmpty" envDefault:"5"`
+}
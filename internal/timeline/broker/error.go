@@ -0,0 +1,8 @@
+package broker
+
+import "errors"
+
+var (
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+	ErrSlowConsumer         = errors.New("slow consumer")
+)
@@ -0,0 +1,45 @@
+// Package memory provides in-process timeline events broker
+// for the single service instance (tests and local development).
+package memory
+
+import (
+	"context"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// Broker delivers the timeline events to the watchers connected to this service instance only.
+type Broker struct {
+	registry *broker.Registry
+}
+
+func NewBroker(cfg broker.Config) *Broker {
+	return &Broker{
+		registry: broker.NewRegistry(cfg, func(xid.ID) {}),
+	}
+}
+
+// Publish publishes the event to the user timeline watchers,
+// it does nothing if the user has no watchers.
+func (b *Broker) Publish(_ context.Context, userID xid.ID, event entity.TimelineEvent) error {
+	if !b.registry.Has(userID) {
+		return nil
+	}
+	b.registry.Dispatch(userID, event)
+	return nil
+}
+
+// Subscribe subscribes to the user timeline events.
+func (b *Broker) Subscribe(_ context.Context, userID xid.ID) (*broker.Subscription, error) {
+	sub, _, err := b.registry.Add(userID)
+	return sub, err
+}
+
+// Run blocks until the context is done: events are dispatched on publish.
+func (b *Broker) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,182 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	"github.com/Karzoug/meower-timeline-service/pkg/redis"
+)
+
+const (
+	subscriptionTimeout = 5 * time.Second
+	// watchedTTL is the lifetime of the watched user mark,
+	// it's refreshed by the instance while the user has subscriptions.
+	watchedTTL = time.Minute
+)
+
+// publishScript publishes the event only if the user is marked as watched
+// by any service instance, so the pushes to the unwatched timelines
+// are not broadcast to the whole cluster.
+var publishScript = goredis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+return redis.call("PUBLISH", ARGV[1], ARGV[2])
+`)
+
+// Broker delivers the timeline events to the watchers connected to any service instance:
+// events are published to the user channel of redis pub/sub and dispatched
+// to the in-process subscriptions of the user.
+type Broker struct {
+	// channelPrefix and watchedPrefix are namespaced by the key prefix of the service,
+	// so the deployments sharing redis don't receive events of each other
+	channelPrefix string
+	watchedPrefix string
+	db            redis.DB
+	pubsub        *goredis.PubSub
+	registry      *broker.Registry
+	mu            sync.Mutex // serializes channel subscription changes
+	logger        zerolog.Logger
+}
+
+func NewBroker(cfg broker.Config, keyPrefix string, db redis.DB, logger zerolog.Logger) *Broker {
+	logger = logger.With().
+		Str("component", "redis broker").
+		Logger()
+
+	b := &Broker{
+		channelPrefix: keyPrefix + ":events:",
+		watchedPrefix: keyPrefix + ":watched:",
+		db:            db,
+		pubsub:        db.Subscribe(context.Background()),
+		logger:        logger,
+	}
+	b.registry = broker.NewRegistry(cfg, func(userID xid.ID) {
+		if err := b.sync(userID); err != nil {
+			b.logger.Error().
+				Err(err).
+				Str("user_id", userID.String()).
+				Msg("failed to unsubscribe from channel")
+		}
+	})
+
+	return b
+}
+
+// Publish publishes the event to the user timeline watchers,
+// it does nothing if the user has no watchers.
+func (b *Broker) Publish(ctx context.Context, userID xid.ID, event entity.TimelineEvent) error {
+	return publishScript.Run(ctx, b.db, []string{b.watchedKey(userID)}, b.channel(userID), event).Err()
+}
+
+// Subscribe subscribes to the user timeline events.
+func (b *Broker) Subscribe(_ context.Context, userID xid.ID) (*broker.Subscription, error) {
+	sub, first, err := b.registry.Add(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if first {
+		if err := b.sync(userID); err != nil {
+			sub.Close()
+			return nil, err
+		}
+	}
+
+	return sub, nil
+}
+
+// Run receives the events from redis and dispatches them to the in-process subscriptions.
+func (b *Broker) Run(ctx context.Context) error {
+	ch := b.pubsub.Channel()
+
+	ticker := time.NewTicker(watchedTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return b.pubsub.Close()
+		case <-ticker.C:
+			if err := b.markWatched(ctx, b.registry.Users()...); err != nil {
+				b.logger.Error().
+					Err(err).
+					Msg("failed to refresh watched users")
+			}
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			userID, err := xid.FromString(strings.TrimPrefix(msg.Channel, b.channelPrefix))
+			if err != nil {
+				b.logger.Error().
+					Err(err).
+					Str("channel", msg.Channel).
+					Msg("invalid channel")
+				continue
+			}
+
+			var event entity.TimelineEvent
+			if err := event.UnmarshalBinary([]byte(msg.Payload)); err != nil {
+				b.logger.Error().
+					Err(err).
+					Str("channel", msg.Channel).
+					Msg("invalid event")
+				continue
+			}
+
+			b.registry.Dispatch(userID, event)
+		}
+	}
+}
+
+// sync subscribes to or unsubscribes from the user channel
+// according to the current subscriptions of the user.
+func (b *Broker) sync(userID xid.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), subscriptionTimeout)
+	defer cancel()
+
+	if b.registry.Has(userID) {
+		if err := b.pubsub.Subscribe(ctx, b.channel(userID)); err != nil {
+			return err
+		}
+		return b.markWatched(ctx, userID)
+	}
+	// the mark is left to expire: the user may be watched on other instances
+	return b.pubsub.Unsubscribe(ctx, b.channel(userID))
+}
+
+// markWatched marks the users as watched for watchedTTL.
+func (b *Broker) markWatched(ctx context.Context, userIDs ...xid.ID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := b.db.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, userID := range userIDs {
+			pipe.Set(ctx, b.watchedKey(userID), 1, watchedTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+func (b *Broker) channel(userID xid.ID) string {
+	return b.channelPrefix + userID.String()
+}
+
+func (b *Broker) watchedKey(userID xid.ID) string {
+	return b.watchedPrefix + userID.String()
+}
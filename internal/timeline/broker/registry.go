@@ -0,0 +1,153 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// Registry is an in-process registry of the timeline events subscriptions.
+type Registry struct {
+	cfg     Config
+	onEmpty func(userID xid.ID)
+
+	mu   sync.Mutex
+	subs map[xid.ID]map[*Subscription]struct{}
+}
+
+// NewRegistry returns a new registry, onEmpty is called
+// when the last subscription of the user is removed.
+func NewRegistry(cfg Config, onEmpty func(userID xid.ID)) *Registry {
+	return &Registry{
+		cfg:     cfg,
+		onEmpty: onEmpty,
+		subs:    make(map[xid.ID]map[*Subscription]struct{}),
+	}
+}
+
+// Add adds a new subscription to the user timeline events,
+// first reports whether it is the only subscription of the user.
+func (r *Registry) Add(userID xid.ID) (sub *Subscription, first bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subs, ok := r.subs[userID]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		r.subs[userID] = subs
+	}
+	if len(subs) >= r.cfg.MaxPerUser {
+		return nil, false, ErrTooManySubscriptions
+	}
+
+	sub = &Subscription{
+		userID:   userID,
+		events:   make(chan entity.TimelineEvent, r.cfg.BufferSize),
+		registry: r,
+	}
+	subs[sub] = struct{}{}
+
+	return sub, len(subs) == 1, nil
+}
+
+// Has reports whether the user has any subscription.
+func (r *Registry) Has(userID xid.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.subs[userID]
+	return ok
+}
+
+// Users returns the users with any subscription.
+func (r *Registry) Users() []xid.ID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]xid.ID, 0, len(r.subs))
+	for userID := range r.subs {
+		users = append(users, userID)
+	}
+	return users
+}
+
+// Dispatch sends the event to all subscriptions of the user without blocking:
+// the subscription with full buffer is closed with ErrSlowConsumer.
+func (r *Registry) Dispatch(userID xid.ID, event entity.TimelineEvent) {
+	r.mu.Lock()
+
+	var empty bool
+	for sub := range r.subs[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			empty = r.remove(sub, ErrSlowConsumer)
+		}
+	}
+
+	r.mu.Unlock()
+
+	if empty {
+		r.onEmpty(userID)
+	}
+}
+
+func (r *Registry) close(sub *Subscription) {
+	r.mu.Lock()
+	empty := r.remove(sub, nil)
+	r.mu.Unlock()
+
+	if empty {
+		r.onEmpty(sub.userID)
+	}
+}
+
+// remove removes the subscription and reports whether the user has no more subscriptions,
+// mutex must be held.
+func (r *Registry) remove(sub *Subscription, err error) bool {
+	subs, ok := r.subs[sub.userID]
+	if !ok {
+		return false
+	}
+	if _, ok := subs[sub]; !ok {
+		return false
+	}
+
+	delete(subs, sub)
+	sub.err = err
+	close(sub.events)
+
+	if len(subs) != 0 {
+		return false
+	}
+	delete(r.subs, sub.userID)
+
+	return true
+}
+
+// Subscription is a subscription to the user timeline events.
+type Subscription struct {
+	userID   xid.ID
+	events   chan entity.TimelineEvent
+	err      error
+	registry *Registry
+}
+
+// Events returns channel of the timeline events,
+// it's closed when the subscription is closed.
+func (s *Subscription) Events() <-chan entity.TimelineEvent {
+	return s.events
+}
+
+// Err returns the reason why the subscription was closed by the registry,
+// it must be called only after the events channel is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close closes the subscription.
+func (s *Subscription) Close() {
+	s.registry.close(s)
+}
@@ -9,6 +9,11 @@ import (
 
 const userKey string = "x-user-id"
 
+// ContextWithUserID returns the context of the request on behalf of the user,
+// nil user id means the request of the service itself: no user is sent then.
 func ContextWithUserID(ctx context.Context, userID xid.ID) context.Context {
+	if userID.IsNil() {
+		return ctx
+	}
 	return metadata.NewOutgoingContext(ctx, metadata.Pairs(userKey, userID.String()))
 }
@@ -2,6 +2,7 @@ package post
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/xid"
 
@@ -41,3 +42,44 @@ func (c Client) ListPostIDsByUserIDs(ctx context.Context, reqUserID xid.ID, user
 
 	return res, nil
 }
+
+func (c Client) BatchGetPosts(ctx context.Context, reqUserID xid.ID, postIDs []xid.ID) ([]entity.PostContent, error) {
+	ctx = grpc.ContextWithUserID(ctx, reqUserID)
+
+	ids := make([]string, len(postIDs))
+	for i := range postIDs {
+		ids[i] = postIDs[i].String()
+	}
+
+	resp, err := c.c.BatchGetPosts(ctx, &postApi.BatchGetPostsRequest{
+		Ids: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]entity.PostContent, 0, len(resp.Posts))
+	for i := range resp.Posts {
+		if resp.Posts[i] == nil {
+			continue
+		}
+		postID, err := xid.FromString(resp.Posts[i].Id)
+		if err != nil {
+			continue
+		}
+		authorID, _ := xid.FromString(resp.Posts[i].AuthorId)
+		var updatedTime time.Time
+		if resp.Posts[i].UpdatedTime != nil {
+			updatedTime = resp.Posts[i].UpdatedTime.AsTime()
+		}
+		res = append(res, entity.PostContent{
+			ID:          postID,
+			AuthorID:    authorID,
+			Text:        resp.Posts[i].Text,
+			UpdatedTime: updatedTime,
+			Deleted:     resp.Posts[i].Deleted,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// FollowedCelebrities are the celebrities followed by the user, their posts are merged
+// into the user timeline at read time.
+type FollowedCelebrities struct {
+	IDs []xid.ID
+	// CheckedAt is the time the followings of the user were checked,
+	// it is zero if they are changed since then.
+	CheckedAt time.Time
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// PostContent is the post as it is provided by post service.
+type PostContent struct {
+	ID          xid.ID
+	AuthorID    xid.ID
+	Text        string
+	UpdatedTime time.Time
+	Deleted     bool
+}
+
+// FullPost is the timeline post together with its content.
+type FullPost struct {
+	Post
+	Text        string
+	UpdatedTime time.Time
+}
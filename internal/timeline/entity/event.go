@@ -0,0 +1,35 @@
+package entity
+
+import "errors"
+
+type EventType uint8
+
+const (
+	EventTypePostInserted EventType = iota + 1
+	EventTypePostDeleted
+)
+
+// TimelineEvent is a change of the user timeline.
+type TimelineEvent struct {
+	Type EventType
+	Post Post
+}
+
+func (e TimelineEvent) MarshalBinary() (data []byte, err error) {
+	post, err := e.Post.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(e.Type)}, post...), nil
+}
+
+func (e *TimelineEvent) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty timeline event")
+	}
+
+	e.Type = EventType(data[0])
+
+	return e.Post.UnmarshalBinary(data[1:])
+}
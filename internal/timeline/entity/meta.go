@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// TimelineSource is the way the cached timeline was built.
+type TimelineSource uint8
+
+const (
+	// TimelineSourceUnknown is the source of the timelines built before the metadata was introduced.
+	TimelineSourceUnknown TimelineSource = iota
+	// TimelineSourceScratch is the timeline built from scratch from posts of all followings.
+	TimelineSourceScratch
+	// TimelineSourceIncremental is the timeline caught up from its previous build.
+	TimelineSourceIncremental
+)
+
+// TimelineMeta is the metadata of the cached timeline, it is stored alongside the timeline
+// and it is zero for the timelines built before the metadata was introduced.
+type TimelineMeta struct {
+	// BuiltAt is the time the posts of the timeline were requested to build it.
+	BuiltAt time.Time
+	// SchemaVersion is the version of the storage schema the timeline is written with.
+	SchemaVersion int
+	Source        TimelineSource
+	// Count is the number of the timeline records.
+	Count int
+}
@@ -1,26 +1,135 @@
 package entity
 
-import "github.com/rs/xid"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/xid"
+)
+
+const (
+	// legacy format without version: [author id][post id][is repost], ids are in text form
+	postLegacyLen = 41
+	// version 1: [version][author id][post id][flags][reposted by id], ids are in text form
+	postV1    byte = 1
+	postV1Len      = 62
+	// version 2: [version][author id][post id][flags][optional fields], ids are raw,
+	// the optional fields follow in the order of their flags, unknown trailing bytes are ignored
+	postV2    byte = 2
+	postV2Len      = 1 + 2*rawIDLen + 1
+	rawIDLen       = 12
+
+	flagRepost     byte = 1
+	flagRepostedBy byte = 2 // version 2 only: reposted by id follows
+	flagRepostID   byte = 4 // version 2 only: repost id follows
+)
+
+var ErrInvalidPostEncoding = errors.New("invalid post encoding")
+
+// PostEncoding is the format the posts are encoded with by MarshalBinary.
+type PostEncoding uint8
+
+const (
+	// PostEncodingV1 is read by all service versions, but it can't keep the repost id:
+	// the reposts written in it are ordered by the original post.
+	PostEncodingV1 PostEncoding = PostEncoding(postV1)
+	// PostEncodingV2 is compact and keeps the repost id, it should be enabled
+	// only when all instances of the service read it.
+	PostEncodingV2 PostEncoding = PostEncoding(postV2)
+)
+
+var postEncoding = PostEncodingV1
+
+// SetPostEncoding sets the format of the posts encoded by MarshalBinary,
+// it should be called on start before any post is encoded.
+func SetPostEncoding(e PostEncoding) error {
+	switch e {
+	case PostEncodingV1, PostEncodingV2:
+		postEncoding = e
+		return nil
+	default:
+		return fmt.Errorf("unknown post encoding: %d", e)
+	}
+}
+
+// PostEncodingKeepsRepostID reports whether the posts encoded by MarshalBinary keep the repost id.
+func PostEncodingKeepsRepostID() bool {
+	return postEncoding != PostEncodingV1
+}
 
 type Post struct {
 	AuthorID xid.ID
 	PostID   xid.ID
 	IsRepost bool
+	// RepostedBy is the user who reposted the post (only if IsRepost).
+	RepostedBy xid.ID
+	// RepostID orders the repost in the timeline instead of the post id (only if IsRepost),
+	// the records written in the formats older than version 2 have none.
+	RepostID xid.ID
+}
+
+// SortID returns the id the record is ordered by in the timeline:
+// the repost id for reposts having it and the post id otherwise.
+func (p Post) SortID() xid.ID {
+	if p.IsRepost && !p.RepostID.IsNil() {
+		return p.RepostID
+	}
+	return p.PostID
 }
 
 func (p Post) MarshalBinary() (data []byte, err error) {
-	b := make([]byte, 41)
+	if postEncoding == PostEncodingV1 {
+		return p.marshalV1(), nil
+	}
+
+	b := make([]byte, postV2Len, postV2Len+2*rawIDLen)
 
-	p.AuthorID.Encode(b[0:20])
-	p.PostID.Encode(b[20:40])
+	b[0] = postV2
+	copy(b[1:13], p.AuthorID.Bytes())
+	copy(b[13:25], p.PostID.Bytes())
 	if p.IsRepost {
-		b[40] = 1
+		b[25] |= flagRepost
+	}
+	if !p.RepostedBy.IsNil() {
+		b[25] |= flagRepostedBy
+		b = append(b, p.RepostedBy.Bytes()...)
+	}
+	if p.IsRepost && !p.RepostID.IsNil() {
+		b[25] |= flagRepostID
+		b = append(b, p.RepostID.Bytes()...)
 	}
 
 	return b, nil
 }
 
+func (p Post) marshalV1() []byte {
+	b := make([]byte, postV1Len)
+
+	b[0] = postV1
+	p.AuthorID.Encode(b[1:21])
+	p.PostID.Encode(b[21:41])
+	if p.IsRepost {
+		b[41] |= flagRepost
+	}
+	p.RepostedBy.Encode(b[42:62])
+
+	return b
+}
+
 func (p *Post) UnmarshalBinary(data []byte) error {
+	switch {
+	case len(data) >= postV2Len && data[0] == postV2:
+		return p.unmarshalV2(data)
+	case len(data) == postLegacyLen:
+		return p.unmarshalLegacy(data)
+	case len(data) == postV1Len && data[0] == postV1:
+		return p.unmarshalV1(data)
+	default:
+		return ErrInvalidPostEncoding
+	}
+}
+
+func (p *Post) unmarshalLegacy(data []byte) error {
 	if err := p.AuthorID.UnmarshalText(data[0:20]); err != nil {
 		return err
 	}
@@ -28,6 +137,73 @@ func (p *Post) UnmarshalBinary(data []byte) error {
 		return err
 	}
 	p.IsRepost = data[40] == 1
+	p.RepostedBy = xid.NilID()
+	p.RepostID = xid.NilID()
+
+	return nil
+}
+
+func (p *Post) unmarshalV1(data []byte) error {
+	if err := p.AuthorID.UnmarshalText(data[1:21]); err != nil {
+		return err
+	}
+	if err := p.PostID.UnmarshalText(data[21:41]); err != nil {
+		return err
+	}
+	p.IsRepost = data[41]&flagRepost != 0
+	if err := p.RepostedBy.UnmarshalText(data[42:62]); err != nil {
+		return err
+	}
+	p.RepostID = xid.NilID()
+
+	return nil
+}
+
+func (p *Post) unmarshalV2(data []byte) error {
+	var err error
+	if p.AuthorID, err = xid.FromBytes(data[1:13]); err != nil {
+		return err
+	}
+	if p.PostID, err = xid.FromBytes(data[13:25]); err != nil {
+		return err
+	}
+	flags := data[25]
+	p.IsRepost = flags&flagRepost != 0
+	p.RepostedBy = xid.NilID()
+	p.RepostID = xid.NilID()
+
+	// optional fields
+	data = data[postV2Len:]
+	if flags&flagRepostedBy != 0 {
+		if len(data) < rawIDLen {
+			return ErrInvalidPostEncoding
+		}
+		if p.RepostedBy, err = xid.FromBytes(data[:rawIDLen]); err != nil {
+			return err
+		}
+		data = data[rawIDLen:]
+	}
+	if flags&flagRepostID != 0 {
+		if len(data) < rawIDLen {
+			return ErrInvalidPostEncoding
+		}
+		if p.RepostID, err = xid.FromBytes(data[:rawIDLen]); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
+
+// IsOutdatedPostEncoding reports whether the post is encoded in the format
+// older than the one produced by MarshalBinary.
+func IsOutdatedPostEncoding(data []byte) bool {
+	switch {
+	case len(data) >= postV2Len && data[0] == postV2:
+		return false
+	case len(data) == postV1Len && data[0] == postV1:
+		return postEncoding != PostEncodingV1
+	default:
+		return true
+	}
+}
@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withPostEncoding sets the format of the posts encoded by MarshalBinary for the test.
+func withPostEncoding(t *testing.T, e PostEncoding) {
+	t.Helper()
+
+	require.NoError(t, SetPostEncoding(e))
+	t.Cleanup(func() { postEncoding = PostEncodingV1 })
+}
+
+func TestPost_BinaryRoundTrip(t *testing.T) {
+	authorID, postID, reposterID, repostID := xid.New(), xid.New(), xid.New(), xid.New()
+
+	post := Post{AuthorID: authorID, PostID: postID}
+	repost := Post{AuthorID: authorID, PostID: postID, IsRepost: true, RepostedBy: reposterID, RepostID: repostID}
+	// the reposts pushed before the repost id was introduced
+	repostWithoutID := Post{AuthorID: authorID, PostID: postID, IsRepost: true, RepostedBy: reposterID}
+
+	tests := []struct {
+		name     string
+		encoding PostEncoding
+		post     Post
+		wantLen  int
+		want     Post
+	}{
+		{
+			name:     "v1 post",
+			encoding: PostEncodingV1,
+			post:     post,
+			wantLen:  postV1Len,
+			want:     post,
+		},
+		{
+			name:     "v1 repost loses repost id",
+			encoding: PostEncodingV1,
+			post:     repost,
+			wantLen:  postV1Len,
+			want:     repostWithoutID,
+		},
+		{
+			name:     "v2 post",
+			encoding: PostEncodingV2,
+			post:     post,
+			wantLen:  postV2Len,
+			want:     post,
+		},
+		{
+			name:     "v2 repost",
+			encoding: PostEncodingV2,
+			post:     repost,
+			wantLen:  postV2Len + 2*rawIDLen,
+			want:     repost,
+		},
+		{
+			name:     "v2 repost without repost id",
+			encoding: PostEncodingV2,
+			post:     repostWithoutID,
+			wantLen:  postV2Len + rawIDLen,
+			want:     repostWithoutID,
+		},
+		{
+			name:     "v2 post ignores repost id",
+			encoding: PostEncodingV2,
+			post:     Post{AuthorID: authorID, PostID: postID, RepostID: repostID},
+			wantLen:  postV2Len,
+			want:     post,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPostEncoding(t, tt.encoding)
+
+			data, err := tt.post.MarshalBinary()
+			require.NoError(t, err)
+			assert.Len(t, data, tt.wantLen)
+			assert.False(t, IsOutdatedPostEncoding(data))
+
+			var got Post
+			require.NoError(t, got.UnmarshalBinary(data))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPost_UnmarshalBinary_OtherEncodings(t *testing.T) {
+	authorID, postID, reposterID, repostID := xid.New(), xid.New(), xid.New(), xid.New()
+
+	legacy := func(isRepost byte) []byte {
+		b := make([]byte, 0, postLegacyLen)
+		b = append(b, authorID.String()...)
+		b = append(b, postID.String()...)
+		return append(b, isRepost)
+	}
+	marshal := func(e PostEncoding, p Post) []byte {
+		withPostEncoding(t, e)
+		data, err := p.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+	repost := Post{AuthorID: authorID, PostID: postID, IsRepost: true, RepostedBy: reposterID, RepostID: repostID}
+	v1Repost := marshal(PostEncodingV1, repost)
+	v2Repost := marshal(PostEncodingV2, repost)
+
+	tests := []struct {
+		name     string
+		encoding PostEncoding // the format the reader encodes with
+		data     []byte
+		want     Post
+		outdated bool
+	}{
+		{
+			name:     "legacy post",
+			encoding: PostEncodingV1,
+			data:     legacy(0),
+			want:     Post{AuthorID: authorID, PostID: postID},
+			outdated: true,
+		},
+		{
+			name:     "legacy repost",
+			encoding: PostEncodingV1,
+			data:     legacy(1),
+			want:     Post{AuthorID: authorID, PostID: postID, IsRepost: true},
+			outdated: true,
+		},
+		{
+			name:     "v1 repost under v2 config",
+			encoding: PostEncodingV2,
+			data:     v1Repost,
+			want:     Post{AuthorID: authorID, PostID: postID, IsRepost: true, RepostedBy: reposterID},
+			outdated: true,
+		},
+		{
+			name:     "v2 repost under v1 config",
+			encoding: PostEncodingV1,
+			data:     v2Repost,
+			want:     repost,
+			outdated: false,
+		},
+		{
+			name:     "v2 repost with unknown trailing fields",
+			encoding: PostEncodingV2,
+			data:     append(append([]byte{}, v2Repost...), 1, 2, 3),
+			want:     repost,
+			outdated: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPostEncoding(t, tt.encoding)
+
+			var got Post
+			require.NoError(t, got.UnmarshalBinary(tt.data))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.outdated, IsOutdatedPostEncoding(tt.data))
+		})
+	}
+}
+
+func TestPost_UnmarshalBinary_Invalid(t *testing.T) {
+	withPostEncoding(t, PostEncodingV2)
+
+	repost := Post{AuthorID: xid.New(), PostID: xid.New(), IsRepost: true, RepostedBy: xid.New(), RepostID: xid.New()}
+	v2Repost, err := repost.MarshalBinary()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "unknown version", data: append([]byte{3}, v2Repost[1:]...)},
+		{name: "v2 without flags", data: v2Repost[:postV2Len-1]},
+		{name: "v2 without reposted by", data: v2Repost[:postV2Len+rawIDLen-1]},
+		{name: "v2 without repost id", data: v2Repost[:postV2Len+2*rawIDLen-1]},
+		{name: "v1 too short", data: append([]byte{postV1}, make([]byte, postV1Len-2)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Post
+			assert.ErrorIs(t, got.UnmarshalBinary(tt.data), ErrInvalidPostEncoding)
+		})
+	}
+}
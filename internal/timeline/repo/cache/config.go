@@ -0,0 +1,14 @@
+package cache
+
+import "time"
+
+type Config struct {
+	// Size is the maximum number of cached first pages, zero disables the cache.
+	Size int `env:"SIZE" envDefault:"10000"`
+	// TTL is how long the first page is cached: it bounds staleness
+	// if the invalidation from other instance is lost.
+	TTL time.Duration `env:"TTL,notEmpty" envDefault:"10s"`
+	// PageSize is the number of the newest timeline records cached,
+	// reads beyond them bypass the cache.
+	PageSize int `env:"PAGE_SIZE,notEmpty" envDefault:"100"`
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// repo is the timeline repo the cache is in front of,
+// see the service repo interface for the methods description.
+type repo interface {
+	ListGet(ctx context.Context, userID, cursor xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error)
+	ListGetNewer(ctx context.Context, userID, sinceID xid.ID, limit int) ([]entity.Post, int, error)
+	ExistedListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) (bool, error)
+	ListMeta(ctx context.Context, userID xid.ID) (entity.TimelineMeta, error)
+	ListSet(ctx context.Context, userID xid.ID, posts []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error
+	ListUpdate(ctx context.Context, userID xid.ID, fn func([]entity.Post) []entity.Post, ttl time.Duration) error
+	ExistedListsPushPosts(ctx context.Context, posts map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error)
+	ExistedListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error
+	ExistedListDelete(ctx context.Context, userID xid.ID) error
+	ColdListGet(ctx context.Context, userID xid.ID) ([]entity.Post, entity.TimelineMeta, error)
+	ColdListDelete(ctx context.Context, userID xid.ID) error
+	UserListGet(ctx context.Context, userIDs []xid.ID, limit int) (map[xid.ID][]entity.Post, error)
+	UserListSet(ctx context.Context, userID xid.ID, posts []entity.Post, ttl time.Duration) error
+	ExistedUserListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) error
+	ExistedUserListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error
+	ExistedUserListDelete(ctx context.Context, userID xid.ID) error
+	CelebrityGet(ctx context.Context, userID xid.ID) (bool, error)
+	CelebritySet(ctx context.Context, userID xid.ID, isCelebrity bool, ttl time.Duration) error
+	CelebrityFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error)
+	FollowedCelebritiesGet(ctx context.Context, userID xid.ID) (entity.FollowedCelebrities, error)
+	FollowedCelebritiesSet(ctx context.Context, userID xid.ID, celebrities entity.FollowedCelebrities, ttl time.Duration) error
+	TombstoneAdd(ctx context.Context, postID xid.ID, ttl time.Duration) error
+	TombstoneFilter(ctx context.Context, postIDs []xid.ID) ([]xid.ID, error)
+	UserTombstoneAdd(ctx context.Context, userID xid.ID, ttl time.Duration) error
+	UserTombstoneFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error)
+}
+
+// invalidations delivers the invalidations of the cached pages between service instances.
+// The instances mark the pages they cache, so the invalidations of the pages
+// not cached by any instance are not published.
+type invalidations interface {
+	// Track marks the page of the user as cached by the instance for ttl.
+	Track(ctx context.Context, userID xid.ID, ttl time.Duration) error
+	// Tracked returns the users whose pages are marked as cached by any instance.
+	Tracked(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error)
+	// Publish notifies all instances that the cached pages of the users are stale.
+	Publish(ctx context.Context, userIDs []xid.ID) error
+	// Receive calls fn for the invalidations published by any instance until the context is done.
+	Receive(ctx context.Context, fn func(userIDs []xid.ID)) error
+}
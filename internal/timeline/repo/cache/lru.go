@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// lru is the cache of the timeline first pages bounded by the number of entries,
+// the least recently used entry is evicted first and the expired entries are not returned.
+type lru struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[xid.ID]*list.Element
+	order   *list.List // front is the most recently used
+	loads   map[xid.ID]*load
+}
+
+type entry struct {
+	userID    xid.ID
+	posts     []entity.Post
+	expiresAt time.Time
+}
+
+// load is the state of the concurrent loads of the user page:
+// the loaded page is not cached if it is invalidated during the load.
+type load struct {
+	count int
+	stale bool
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[xid.ID]*list.Element),
+		order:   list.New(),
+		loads:   make(map[xid.ID]*load),
+	}
+}
+
+func (c *lru) get(userID xid.ID) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[userID]
+	if !ok {
+		return entry{}, false
+	}
+	e := el.Value.(*entry) //nolint:forcetypeassert
+	if !c.now().Before(e.expiresAt) {
+		c.removeElement(el)
+		return entry{}, false
+	}
+	c.order.MoveToFront(el)
+
+	return *e, true
+}
+
+// startLoad registers the load of the user page, done must be called with the loaded page
+// (or nil on failure) to cache it if it is not invalidated meanwhile.
+func (c *lru) startLoad(userID xid.ID) (done func(posts []entity.Post)) {
+	c.mu.Lock()
+	l, ok := c.loads[userID]
+	if !ok {
+		l = &load{}
+		c.loads[userID] = l
+	}
+	l.count++
+	c.mu.Unlock()
+
+	return func(posts []entity.Post) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if posts != nil && !l.stale {
+			c.add(entry{
+				userID:    userID,
+				posts:     posts,
+				expiresAt: c.now().Add(c.ttl),
+			})
+		}
+
+		l.count--
+		if l.count == 0 {
+			delete(c.loads, userID)
+		}
+	}
+}
+
+func (c *lru) add(e entry) {
+	if el, ok := c.entries[e.userID]; ok {
+		el.Value = &e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[e.userID] = c.order.PushFront(&e)
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// remove invalidates the cached and the being loaded page of the user.
+func (c *lru) remove(userID xid.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[userID]; ok {
+		c.removeElement(el)
+	}
+	if l, ok := c.loads[userID]; ok {
+		l.stale = true
+	}
+}
+
+func (c *lru) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).userID) //nolint:forcetypeassert
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+
+	"github.com/Karzoug/meower-timeline-service/pkg/redis"
+)
+
+// RedisInvalidations delivers the invalidations through redis pub/sub,
+// the message is the concatenation of the user ids in binary form.
+// Messages published while the instance is disconnected are lost, so cache TTL bounds staleness.
+// The cached pages are marked by the expiring keys of the users.
+type RedisInvalidations struct {
+	// channel and trackKeyPrefix are namespaced by the key prefix of the service,
+	// so the deployments sharing redis don't receive invalidations of each other
+	channel        string
+	trackKeyPrefix string
+	db             redis.DB
+	logger         zerolog.Logger
+}
+
+func NewRedisInvalidations(keyPrefix string, db redis.DB, logger zerolog.Logger) RedisInvalidations {
+	return RedisInvalidations{
+		channel:        keyPrefix + ":cache:invalidations",
+		trackKeyPrefix: keyPrefix + ":cache:tracked:",
+		db:             db,
+		logger: logger.With().
+			Str("component", "redis cache invalidations").
+			Logger(),
+	}
+}
+
+func (i RedisInvalidations) Track(ctx context.Context, userID xid.ID, ttl time.Duration) error {
+	return i.db.Set(ctx, i.trackKeyPrefix+userID.String(), 1, ttl).Err()
+}
+
+func (i RedisInvalidations) Tracked(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	pipe := i.db.Pipeline()
+
+	cmds := make([]*goredis.IntCmd, len(userIDs))
+	for j := range userIDs {
+		cmds[j] = pipe.Exists(ctx, i.trackKeyPrefix+userIDs[j].String())
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make([]xid.ID, 0, len(userIDs))
+	for j := range cmds {
+		if cmds[j].Val() != 0 {
+			res = append(res, userIDs[j])
+		}
+	}
+
+	return res, nil
+}
+
+func (i RedisInvalidations) Publish(ctx context.Context, userIDs []xid.ID) error {
+	payload := make([]byte, 0, len(userIDs)*len(xid.NilID()))
+	for j := range userIDs {
+		payload = append(payload, userIDs[j].Bytes()...)
+	}
+
+	return i.db.Publish(ctx, i.channel, payload).Err()
+}
+
+func (i RedisInvalidations) Receive(ctx context.Context, fn func(userIDs []xid.ID)) error {
+	pubsub := i.db.Subscribe(ctx, i.channel)
+	defer pubsub.Close() //nolint:errcheck
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			userIDs, err := decodeUserIDs([]byte(msg.Payload))
+			if err != nil {
+				i.logger.Error().
+					Err(err).
+					Msg("invalid invalidation message")
+				continue
+			}
+			fn(userIDs)
+		}
+	}
+}
+
+func decodeUserIDs(data []byte) ([]xid.ID, error) {
+	size := len(xid.NilID())
+
+	userIDs := make([]xid.ID, 0, len(data)/size)
+	for len(data) >= size {
+		id, err := xid.FromBytes(data[:size])
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return nil, xid.ErrInvalidID
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,188 @@
+// Package cache provides in-process cache of the timeline first pages in front of the timeline repo.
+package cache
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+const publishTimeout = 5 * time.Second
+
+// Repo caches the first pages of the timelines read from the repo, the page of the user
+// is invalidated by the mutations of the user timeline made by any service instance.
+// Other methods are passed to the repo as is.
+type Repo struct {
+	repo
+	pages         *lru // nil if the cache is disabled
+	pageSize      int
+	trackTTL      time.Duration // the mark of the cached page outlives the page loaded within its TTL
+	invalidations invalidations // nil if there is the only instance
+	logger        zerolog.Logger
+}
+
+func NewRepo(cfg Config, r repo, invalidations invalidations, logger zerolog.Logger) *Repo {
+	logger = logger.With().
+		Str("component", "timeline cache").
+		Logger()
+
+	c := &Repo{
+		repo:          r,
+		pageSize:      cfg.PageSize,
+		trackTTL:      2 * cfg.TTL,
+		invalidations: invalidations,
+		logger:        logger,
+	}
+	if cfg.Size > 0 {
+		c.pages = newLRU(cfg.Size, cfg.TTL)
+	}
+
+	return c
+}
+
+// Run applies the invalidations published by other instances until the context is done.
+func (c *Repo) Run(ctx context.Context) error {
+	if c.pages == nil || c.invalidations == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	return c.invalidations.Receive(ctx, func(userIDs []xid.ID) {
+		for i := range userIDs {
+			c.pages.remove(userIDs[i])
+		}
+	})
+}
+
+// ListGet returns the first page from the cache if the reads of the context tolerate lag
+// (ttl is prolonged only on cache miss then).
+func (c *Repo) ListGet(ctx context.Context, userID, cursor xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error) {
+	if c.pages == nil || !cursor.IsNil() || offset+limit > c.pageSize || !repoerr.IsReplicaRead(ctx) {
+		return c.repo.ListGet(ctx, userID, cursor, offset, limit, ttl)
+	}
+
+	if e, ok := c.pages.get(userID); ok {
+		return window(e.posts, offset, limit), nil
+	}
+
+	// the page is marked before it is read, so the mutations made after the read are published
+	if c.invalidations != nil {
+		if err := c.invalidations.Track(ctx, userID, c.trackTTL); err != nil {
+			c.logger.Error().
+				Err(err).
+				Str("user_id", userID.String()).
+				Msg("failed to mark cached page")
+			return c.repo.ListGet(ctx, userID, cursor, offset, limit, ttl)
+		}
+	}
+
+	done := c.pages.startLoad(userID)
+	posts, err := c.repo.ListGet(ctx, userID, cursor, 0, c.pageSize, ttl)
+	if err != nil {
+		done(nil)
+		return nil, err
+	}
+	done(posts)
+
+	return window(posts, offset, limit), nil
+}
+
+func (c *Repo) ExistedListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) (bool, error) {
+	pushed, err := c.repo.ExistedListPushPost(ctx, userID, post, limit)
+	if pushed || err != nil {
+		c.invalidate(ctx, userID)
+	}
+
+	return pushed, err
+}
+
+func (c *Repo) ListSet(ctx context.Context, userID xid.ID, posts []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error {
+	defer c.invalidate(ctx, userID)
+
+	return c.repo.ListSet(ctx, userID, posts, meta, ttl)
+}
+
+func (c *Repo) ListUpdate(ctx context.Context, userID xid.ID, fn func([]entity.Post) []entity.Post, ttl time.Duration) error {
+	defer c.invalidate(ctx, userID)
+
+	return c.repo.ListUpdate(ctx, userID, fn, ttl)
+}
+
+func (c *Repo) ExistedListsPushPosts(ctx context.Context, posts map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error) {
+	res, err := c.repo.ExistedListsPushPosts(ctx, posts, limit)
+
+	userIDs := make([]xid.ID, 0, len(posts))
+	for userID := range posts {
+		// timelines not in cache of the instance may be in cache of others
+		if err != nil || slices.Contains(res[userID], true) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	c.invalidate(ctx, userIDs...)
+
+	return res, err
+}
+
+func (c *Repo) ExistedListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	defer c.invalidate(ctx, userID)
+
+	return c.repo.ExistedListDeletePost(ctx, userID, post)
+}
+
+func (c *Repo) ExistedListDelete(ctx context.Context, userID xid.ID) error {
+	defer c.invalidate(ctx, userID)
+
+	return c.repo.ExistedListDelete(ctx, userID)
+}
+
+// invalidate removes the cached pages of the users and notifies other instances
+// if any of them may cache the pages.
+func (c *Repo) invalidate(ctx context.Context, userIDs ...xid.ID) {
+	if c.pages == nil || len(userIDs) == 0 {
+		return
+	}
+
+	for i := range userIDs {
+		c.pages.remove(userIDs[i])
+	}
+
+	if c.invalidations == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+
+	tracked, err := c.invalidations.Tracked(ctx, userIDs)
+	if err != nil {
+		c.logger.Error().
+			Err(err).
+			Int("users", len(userIDs)).
+			Msg("failed to check cached pages")
+		tracked = userIDs
+	}
+	if len(tracked) == 0 {
+		return
+	}
+
+	if err := c.invalidations.Publish(ctx, tracked); err != nil {
+		// the stale page is evicted by TTL
+		c.logger.Error().
+			Err(err).
+			Int("users", len(userIDs)).
+			Msg("failed to publish cache invalidation")
+	}
+}
+
+func window(posts []entity.Post, offset, limit int) []entity.Post {
+	if offset >= len(posts) {
+		return []entity.Post{}
+	}
+	return slices.Clone(posts[offset:min(offset+limit, len(posts))])
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/repo/memory"
+)
+
+// localInvalidations delivers the invalidations between caches in the same process.
+type localInvalidations struct {
+	ch      chan []xid.ID
+	mu      sync.Mutex
+	tracked map[xid.ID]struct{}
+}
+
+func newLocalInvalidations() *localInvalidations {
+	return &localInvalidations{
+		ch:      make(chan []xid.ID, 16),
+		tracked: make(map[xid.ID]struct{}),
+	}
+}
+
+func (i *localInvalidations) Track(_ context.Context, userID xid.ID, _ time.Duration) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.tracked[userID] = struct{}{}
+	return nil
+}
+
+func (i *localInvalidations) Tracked(_ context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var res []xid.ID
+	for _, userID := range userIDs {
+		if _, ok := i.tracked[userID]; ok {
+			res = append(res, userID)
+		}
+	}
+	return res, nil
+}
+
+func (i *localInvalidations) Publish(_ context.Context, userIDs []xid.ID) error {
+	i.ch <- userIDs
+	return nil
+}
+
+func (i *localInvalidations) Receive(ctx context.Context, fn func(userIDs []xid.ID)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case userIDs := <-i.ch:
+			fn(userIDs)
+		}
+	}
+}
+
+func TestRepo_ListGet(t *testing.T) {
+	ctx := repoerr.WithReplicaRead(context.TODO())
+	r := memory.NewTimelineRepo()
+	invalidations := newLocalInvalidations()
+
+	// two instances share the repo
+	cfg := Config{Size: 1, TTL: time.Hour, PageSize: 2}
+	c1 := NewRepo(cfg, r, invalidations, zerolog.Nop())
+	c2 := NewRepo(cfg, r, invalidations, zerolog.Nop())
+
+	userID := xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+
+	require.NoError(t, r.ListSet(ctx, userID, []entity.Post{post1}, entity.TimelineMeta{}, time.Hour))
+
+	res, err := c2.ListGet(ctx, userID, xid.NilID(), 0, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post1}, res)
+
+	// the first instance pushes the post
+	pushed, err := c1.ExistedListPushPost(ctx, userID, post2, 10)
+	require.NoError(t, err)
+	require.True(t, pushed)
+
+	// the second instance returns the cached page until invalidation is received
+	res, err = c2.ListGet(ctx, userID, xid.NilID(), 0, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post1}, res)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go c2.Run(runCtx) //nolint:errcheck
+
+	assert.Eventually(t, func() bool {
+		res, err = c2.ListGet(ctx, userID, xid.NilID(), 0, 2, nil)
+		return err == nil && len(res) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []entity.Post{post2, post1}, res)
+
+	// the reads beyond the page and the reads of own writes bypass the cache
+	require.NoError(t, r.ListSet(ctx, userID, nil, entity.TimelineMeta{}, time.Hour))
+
+	res, err = c2.ListGet(ctx, userID, xid.NilID(), 1, 2, nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	res, err = c2.ListGet(repoerr.WithPrimaryRead(ctx), userID, xid.NilID(), 0, 2, nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func TestRepo_ExistedListsPushPosts(t *testing.T) {
+	ctx := repoerr.WithReplicaRead(context.TODO())
+	r := memory.NewTimelineRepo()
+	invalidations := newLocalInvalidations()
+	c := NewRepo(Config{Size: 10, TTL: time.Hour, PageSize: 2}, r, invalidations, zerolog.Nop())
+
+	cachedUserID := xid.New()
+	posts := map[xid.ID][]entity.Post{
+		cachedUserID: {{PostID: xid.New(), AuthorID: xid.New()}},
+	}
+	for range 100 {
+		posts[xid.New()] = []entity.Post{{PostID: xid.New(), AuthorID: xid.New()}}
+	}
+	for userID := range posts {
+		require.NoError(t, r.ListSet(ctx, userID, nil, entity.TimelineMeta{}, time.Hour))
+	}
+
+	_, err := c.ListGet(ctx, cachedUserID, xid.NilID(), 0, 2, nil)
+	require.NoError(t, err)
+
+	// the fan-out invalidates only the page cached by some instance
+	_, err = c.ExistedListsPushPosts(ctx, posts, 10)
+	require.NoError(t, err)
+
+	require.Len(t, invalidations.ch, 1)
+	assert.Equal(t, []xid.ID{cachedUserID}, <-invalidations.ch)
+}
+
+func Test_lru(t *testing.T) {
+	c := newLRU(2, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	user1, user2, user3 := xid.New(), xid.New(), xid.New()
+	posts := []entity.Post{{PostID: xid.New(), AuthorID: xid.New()}}
+
+	for _, userID := range []xid.ID{user1, user2} {
+		c.startLoad(userID)(posts)
+	}
+	_, ok := c.get(user1)
+	require.True(t, ok)
+
+	// the least recently used is evicted
+	c.startLoad(user3)(posts)
+	_, ok = c.get(user2)
+	assert.False(t, ok)
+	_, ok = c.get(user1)
+	assert.True(t, ok)
+
+	// the page invalidated during the load is not cached
+	done := c.startLoad(user2)
+	c.remove(user2)
+	done(posts)
+	_, ok = c.get(user2)
+	assert.False(t, ok)
+
+	// expired page is not returned
+	now = now.Add(time.Minute)
+	_, ok = c.get(user1)
+	assert.False(t, ok)
+}
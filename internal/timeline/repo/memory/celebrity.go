@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+type celebrityCheck struct {
+	isCelebrity bool
+	expiresAt   time.Time
+}
+
+type followedCelebrities struct {
+	celebrities entity.FollowedCelebrities
+	expiresAt   time.Time
+}
+
+func (r *repo) CelebrityGet(_ context.Context, userID xid.ID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	check, ok := r.celebrityChecks[userID]
+	if !ok || !r.now().Before(check.expiresAt) {
+		return false, repoerr.ErrNotFound
+	}
+
+	return check.isCelebrity, nil
+}
+
+func (r *repo) CelebritySet(_ context.Context, userID xid.ID, isCelebrity bool, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.celebrityChecks[userID] = celebrityCheck{
+		isCelebrity: isCelebrity,
+		expiresAt:   r.now().Add(ttl),
+	}
+	if isCelebrity {
+		r.celebrities[userID] = struct{}{}
+	} else {
+		delete(r.celebrities, userID)
+	}
+
+	return nil
+}
+
+func (r *repo) CelebrityFilter(_ context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]xid.ID, 0)
+	for i := range userIDs {
+		if _, ok := r.celebrities[userIDs[i]]; ok {
+			res = append(res, userIDs[i])
+		}
+	}
+
+	return res, nil
+}
+
+func (r *repo) FollowedCelebritiesGet(_ context.Context, userID xid.ID) (entity.FollowedCelebrities, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.followed[userID]
+	if !ok || !r.now().Before(f.expiresAt) {
+		return entity.FollowedCelebrities{}, repoerr.ErrNotFound
+	}
+
+	return entity.FollowedCelebrities{
+		IDs:       slices.Clone(f.celebrities.IDs),
+		CheckedAt: f.celebrities.CheckedAt,
+	}, nil
+}
+
+func (r *repo) FollowedCelebritiesSet(_ context.Context, userID xid.ID, celebrities entity.FollowedCelebrities, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.followed[userID] = followedCelebrities{
+		celebrities: entity.FollowedCelebrities{
+			IDs:       slices.Clone(celebrities.IDs),
+			CheckedAt: celebrities.CheckedAt,
+		},
+		expiresAt: r.now().Add(ttl),
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// setCold replaces the cold copy of the timeline, the copy without the build time
+// can't be caught up, so it is removed.
+func (r *repo) setCold(userID xid.ID, records []entity.Post, meta entity.TimelineMeta) {
+	if meta.BuiltAt.IsZero() {
+		delete(r.coldTimelines, userID)
+		return
+	}
+
+	r.setList(r.coldTimelines, userID, records, meta, 0)
+}
+
+func (r *repo) ColdListGet(_ context.Context, userID xid.ID) ([]entity.Post, entity.TimelineMeta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.coldTimelines[userID]
+	if !ok {
+		return nil, entity.TimelineMeta{}, repoerr.ErrNotFound
+	}
+
+	return slices.Clone(l.records), l.meta, nil
+}
+
+func (r *repo) ColdListDelete(_ context.Context, userID xid.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.coldTimelines, userID)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// maxUpdateRetries is the number of optimistic update attempts of the timeline
+// changed concurrently before giving up.
+const maxUpdateRetries = 10
+
+var errTooManyUpdateRetries = errors.New("timeline is changed concurrently too often")
+
+func (r *repo) ExistedListPushPost(_ context.Context, userID xid.ID, record entity.Post, limit int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.getList(r.timelines, userID)
+	if !ok {
+		return false, nil
+	}
+
+	return l.push(record, limit), nil
+}
+
+func (r *repo) ExistedListsPushPosts(_ context.Context, records map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[xid.ID][]bool, len(records))
+	for userID, posts := range records {
+		res[userID] = make([]bool, len(posts))
+
+		l, ok := r.getList(r.timelines, userID)
+		if !ok {
+			continue
+		}
+		for i := range posts {
+			res[userID][i] = l.push(posts[i], limit)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *repo) ListSet(_ context.Context, userID xid.ID, records []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setList(r.timelines, userID, records, meta, ttl)
+	r.setCold(userID, records, meta)
+
+	return nil
+}
+
+// ListUpdate calls fn without holding the lock, so fn may use the repo,
+// and retries if the timeline is changed meanwhile (as redis WATCH does).
+func (r *repo) ListUpdate(_ context.Context, userID xid.ID, fn func([]entity.Post) []entity.Post, ttl time.Duration) error {
+	for range maxUpdateRetries {
+		r.mu.Lock()
+		l, ok := r.getList(r.timelines, userID)
+		if !ok {
+			r.mu.Unlock()
+			return repoerr.ErrNotFound
+		}
+		posts, meta, version := slices.Clone(l.records), l.meta, l.version
+		r.mu.Unlock()
+
+		res := fn(posts)
+
+		r.mu.Lock()
+		l, ok = r.getList(r.timelines, userID)
+		if ok && l.version == version {
+			// the rewritten timeline keeps its build metadata
+			r.setList(r.timelines, userID, res, meta, ttl)
+			r.setCold(userID, res, meta)
+			r.mu.Unlock()
+			return nil
+		}
+		r.mu.Unlock()
+	}
+
+	return errTooManyUpdateRetries
+}
+
+func (r *repo) ExistedListDeletePost(_ context.Context, userID xid.ID, post entity.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.getList(r.timelines, userID); ok {
+		l.deletePost(post.PostID)
+	}
+	if l, ok := r.coldTimelines[userID]; ok {
+		l.deletePost(post.PostID)
+	}
+
+	return nil
+}
+
+func (r *repo) ExistedListDelete(_ context.Context, userID xid.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.timelines, userID)
+	delete(r.coldTimelines, userID)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+func Test_repo_ExistedListPushPost(t *testing.T) {
+	ctx := context.TODO()
+	r := NewTimelineRepo()
+
+	userID := xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post3 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+
+	// not existed list is not created
+	pushed, err := r.ExistedListPushPost(ctx, userID, post1, 10)
+	require.NoError(t, err)
+	assert.False(t, pushed)
+
+	_, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.ErrorIs(t, err, repoerr.ErrNotFound)
+
+	// empty list exists
+	require.NoError(t, r.ListSet(ctx, userID, nil, entity.TimelineMeta{}, time.Hour))
+
+	resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Empty(t, resp)
+
+	// out of order push is inserted at chronological position
+	for _, post := range []entity.Post{post3, post1, post2} {
+		pushed, err = r.ExistedListPushPost(ctx, userID, post, 10)
+		require.NoError(t, err)
+		assert.True(t, pushed)
+	}
+
+	// duplicate is ignored
+	pushed, err = r.ExistedListPushPost(ctx, userID, entity.Post{PostID: post2.PostID, AuthorID: xid.New(), IsRepost: true}, 10)
+	require.NoError(t, err)
+	assert.False(t, pushed)
+
+	resp, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post3, post2, post1}, resp)
+
+	// list is trimmed to limit records
+	post4 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	pushed, err = r.ExistedListPushPost(ctx, userID, post4, 3)
+	require.NoError(t, err)
+	assert.True(t, pushed)
+
+	resp, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post4, post3, post2}, resp)
+
+	// cursor pagination
+	resp, err = r.ListGet(ctx, userID, post3.PostID, 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post2}, resp)
+}
+
+func Test_repo_ListExpire(t *testing.T) {
+	ctx := context.TODO()
+	r := NewTimelineRepo()
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	userID := xid.New()
+	require.NoError(t, r.ListSet(ctx, userID, []entity.Post{{PostID: xid.New(), AuthorID: xid.New()}}, entity.TimelineMeta{}, time.Hour))
+
+	// reading with ttl prolongs the list
+	now = now.Add(50 * time.Minute)
+	ttl := time.Hour
+	_, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, &ttl)
+	require.NoError(t, err)
+
+	now = now.Add(50 * time.Minute)
+	_, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+
+	now = now.Add(10 * time.Minute)
+	_, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.ErrorIs(t, err, repoerr.ErrNotFound)
+}
+
+func Test_repo_ListUpdate_ConcurrentPush(t *testing.T) {
+	ctx := context.TODO()
+	r := NewTimelineRepo()
+
+	userID := xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post3 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+
+	require.NoError(t, r.ListSet(ctx, userID, []entity.Post{post2, post1}, entity.TimelineMeta{}, time.Hour))
+
+	var calls int
+	err := r.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		calls++
+		if calls == 1 {
+			// concurrent push between read and write of the timeline
+			_, err := r.ExistedListPushPost(ctx, userID, post3, 10)
+			require.NoError(t, err)
+		}
+		return posts[:len(posts)-1]
+	}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post3, post2}, resp)
+}
+
+func Test_repo_ListMeta(t *testing.T) {
+	ctx := context.TODO()
+	r := NewTimelineRepo()
+
+	userID := xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+
+	_, err := r.ListMeta(ctx, userID)
+	require.ErrorIs(t, err, repoerr.ErrNotFound)
+
+	builtAt := time.Now().Add(-time.Minute)
+	require.NoError(t, r.ListSet(ctx, userID, []entity.Post{post1}, entity.TimelineMeta{
+		BuiltAt: builtAt,
+		Source:  entity.TimelineSourceScratch,
+	}, time.Hour))
+
+	meta, err := r.ListMeta(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.TimelineMeta{
+		BuiltAt:       builtAt,
+		SchemaVersion: schemaVersion,
+		Source:        entity.TimelineSourceScratch,
+		Count:         1,
+	}, meta)
+
+	// count follows the changes, the build metadata is kept by the update
+	_, err = r.ExistedListPushPost(ctx, userID, post2, 10)
+	require.NoError(t, err)
+	require.NoError(t, r.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		return posts
+	}, time.Hour))
+
+	meta, err = r.ListMeta(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, builtAt, meta.BuiltAt)
+	assert.Equal(t, entity.TimelineSourceScratch, meta.Source)
+	assert.Equal(t, 2, meta.Count)
+
+	require.NoError(t, r.ExistedListDeletePost(ctx, userID, post1))
+
+	meta, err = r.ListMeta(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, meta.Count)
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+func (r *repo) ListGet(_ context.Context, userID, cursor xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.getList(r.timelines, userID)
+	if !ok {
+		return nil, repoerr.ErrNotFound
+	}
+
+	if ttl != nil {
+		l.expiresAt = r.now().Add(*ttl)
+	}
+
+	start := offset
+	if !cursor.IsNil() {
+		i := indexOlder(l.records, cursor)
+		if i == -1 {
+			return []entity.Post{}, nil
+		}
+		start += i
+	}
+	if start >= len(l.records) || limit <= 0 {
+		return []entity.Post{}, nil
+	}
+
+	return slices.Clone(l.records[start:min(start+limit, len(l.records))]), nil
+}
+
+func (r *repo) ListGetNewer(_ context.Context, userID, sinceID xid.ID, limit int) ([]entity.Post, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.getList(r.timelines, userID)
+	if !ok {
+		return nil, 0, repoerr.ErrNotFound
+	}
+
+	count := len(l.records)
+	for i := range l.records {
+		if l.records[i].SortID().Compare(sinceID) <= 0 {
+			count = i
+			break
+		}
+	}
+
+	return slices.Clone(l.records[:min(count, limit)]), count, nil
+}
+
+func (r *repo) ListMeta(_ context.Context, userID xid.ID) (entity.TimelineMeta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.getList(r.timelines, userID)
+	if !ok {
+		return entity.TimelineMeta{}, repoerr.ErrNotFound
+	}
+
+	return l.meta, nil
+}
+
+// indexOlder returns index of the first record ordered after the cursor (sort id) or -1.
+func indexOlder(records []entity.Post, cursor xid.ID) int {
+	for i := range records {
+		if records[i].SortID().Compare(cursor) < 0 {
+			return i
+		}
+	}
+	return -1
+}
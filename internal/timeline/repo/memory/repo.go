@@ -0,0 +1,128 @@
+// Package memory provides in-memory implementation of the timeline repo
+// with the same semantics as the redis one. It is intended for unit tests
+// and for local development without redis.
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// schemaVersion is the version of the timeline metadata schema, the in-memory repo has the only one.
+const schemaVersion = 1
+
+// list emulates redis list of the records ordered from newest to oldest together with its metadata,
+// the list exists even if it has no records.
+type list struct {
+	records   []entity.Post
+	meta      entity.TimelineMeta
+	expiresAt time.Time // zero if the list never expires
+	version   uint64    // incremented on each change
+}
+
+type repo struct {
+	mu              sync.Mutex
+	timelines       map[xid.ID]*list
+	coldTimelines   map[xid.ID]*list // never expire
+	userTimelines   map[xid.ID]*list
+	celebrityChecks map[xid.ID]celebrityCheck
+	celebrities     map[xid.ID]struct{}
+	followed        map[xid.ID]followedCelebrities
+	tombstones      map[xid.ID]time.Time
+	userTombstones  map[xid.ID]time.Time
+	now             func() time.Time
+}
+
+func NewTimelineRepo() *repo {
+	return &repo{
+		timelines:       make(map[xid.ID]*list),
+		coldTimelines:   make(map[xid.ID]*list),
+		userTimelines:   make(map[xid.ID]*list),
+		celebrityChecks: make(map[xid.ID]celebrityCheck),
+		celebrities:     make(map[xid.ID]struct{}),
+		followed:        make(map[xid.ID]followedCelebrities),
+		tombstones:      make(map[xid.ID]time.Time),
+		userTombstones:  make(map[xid.ID]time.Time),
+		now:             time.Now,
+	}
+}
+
+// getList returns not expired list, it removes expired one as redis does.
+func (r *repo) getList(lists map[xid.ID]*list, id xid.ID) (*list, bool) {
+	l, ok := lists[id]
+	if !ok {
+		return nil, false
+	}
+	if !l.expiresAt.IsZero() && !r.now().Before(l.expiresAt) {
+		delete(lists, id)
+		return nil, false
+	}
+	return l, true
+}
+
+// setList replaces the list with the records and the metadata
+// (its schema version and count are set here).
+func (r *repo) setList(lists map[xid.ID]*list, id xid.ID, records []entity.Post, meta entity.TimelineMeta, ttl time.Duration) {
+	var version uint64
+	if l, ok := lists[id]; ok {
+		version = l.version
+	}
+
+	meta.SchemaVersion = schemaVersion
+	meta.Count = len(records)
+
+	l := &list{
+		records: slices.Clone(records),
+		meta:    meta,
+		version: version + 1,
+	}
+	if ttl > 0 {
+		l.expiresAt = r.now().Add(ttl)
+	}
+
+	lists[id] = l
+}
+
+// push inserts the record at its chronological position (by sort id) if the list
+// does not contain the record with the same post id yet and trims the list
+// to limit records. It reports whether the record was inserted.
+func (l *list) push(record entity.Post, limit int64) bool {
+	if slices.ContainsFunc(l.records, func(p entity.Post) bool {
+		return p.PostID.Compare(record.PostID) == 0
+	}) {
+		return false
+	}
+
+	i := slices.IndexFunc(l.records, func(p entity.Post) bool {
+		return p.SortID().Compare(record.SortID()) < 0
+	})
+	if i == -1 {
+		i = len(l.records)
+	}
+
+	l.records = slices.Insert(l.records, i, record)
+	if int64(len(l.records)) > limit {
+		l.records = l.records[:limit]
+	}
+	l.meta.Count = len(l.records)
+	l.version++
+
+	return true
+}
+
+// deletePost removes all records with the post id (the post itself and its reposts).
+func (l *list) deletePost(postID xid.ID) {
+	n := len(l.records)
+	l.records = slices.DeleteFunc(l.records, func(p entity.Post) bool {
+		return p.PostID.Compare(postID) == 0
+	})
+	if len(l.records) != n {
+		l.meta.Count = len(l.records)
+		l.version++
+	}
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+func (r *repo) TombstoneAdd(_ context.Context, postID xid.ID, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addTombstone(r.tombstones, postID, ttl)
+
+	return nil
+}
+
+func (r *repo) TombstoneFilter(_ context.Context, postIDs []xid.ID) ([]xid.ID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filterTombstones(r.tombstones, postIDs), nil
+}
+
+func (r *repo) UserTombstoneAdd(_ context.Context, userID xid.ID, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addTombstone(r.userTombstones, userID, ttl)
+
+	return nil
+}
+
+func (r *repo) UserTombstoneFilter(_ context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.filterTombstones(r.userTombstones, userIDs), nil
+}
+
+func (r *repo) addTombstone(tombstones map[xid.ID]time.Time, id xid.ID, ttl time.Duration) {
+	now := r.now()
+
+	// remove expired tombstones
+	for id, expiresAt := range tombstones {
+		if expiresAt.Before(now) {
+			delete(tombstones, id)
+		}
+	}
+	if _, ok := tombstones[id]; !ok {
+		tombstones[id] = now.Add(ttl)
+	}
+}
+
+func (r *repo) filterTombstones(tombstones map[xid.ID]time.Time, ids []xid.ID) []xid.ID {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := r.now()
+	res := make([]xid.ID, 0)
+	for i := range ids {
+		if expiresAt, ok := tombstones[ids[i]]; ok && !expiresAt.Before(now) {
+			res = append(res, ids[i])
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func (r *repo) UserListGet(_ context.Context, userIDs []xid.ID, limit int) (map[xid.ID][]entity.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[xid.ID][]entity.Post, len(userIDs))
+	for i := range userIDs {
+		l, ok := r.getList(r.userTimelines, userIDs[i])
+		if !ok {
+			// not found user timeline in cache
+			continue
+		}
+
+		res[userIDs[i]] = slices.Clone(l.records[:min(limit, len(l.records))])
+	}
+
+	return res, nil
+}
+
+func (r *repo) UserListSet(_ context.Context, userID xid.ID, records []entity.Post, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setList(r.userTimelines, userID, records, entity.TimelineMeta{
+		BuiltAt: r.now(),
+		Source:  entity.TimelineSourceScratch,
+	}, ttl)
+
+	return nil
+}
+
+func (r *repo) ExistedUserListPushPost(_ context.Context, userID xid.ID, record entity.Post, limit int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.getList(r.userTimelines, userID); ok {
+		l.push(record, limit)
+	}
+
+	return nil
+}
+
+func (r *repo) ExistedUserListDeletePost(_ context.Context, userID xid.ID, record entity.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.getList(r.userTimelines, userID); ok {
+		l.deletePost(record.PostID)
+	}
+
+	return nil
+}
+
+func (r *repo) ExistedUserListDelete(_ context.Context, userID xid.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.userTimelines, userID)
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package repo
+
+import "context"
+
+type readPreferenceKey struct{}
+
+// WithReplicaRead returns the context whose reads may be served by replicas:
+// the result may lag behind the recent writes.
+func WithReplicaRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, true)
+}
+
+// WithPrimaryRead returns the context whose reads are pinned to the primary,
+// so they see the own writes made before.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, false)
+}
+
+// IsReplicaRead reports whether reads of the context may be served by replicas,
+// by default they are served by the primary.
+func IsReplicaRead(ctx context.Context) bool {
+	v, _ := ctx.Value(readPreferenceKey{}).(bool)
+	return v
+}
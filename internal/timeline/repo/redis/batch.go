@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func (r repo) ExistedListsPushPosts(ctx context.Context, records map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error) {
+	if r.legacyKeys {
+		keys := make([]legacyKey, 0, 2*len(records))
+		for userID := range records {
+			keys = append(keys, r.homeLegacyKeys(userID)...)
+		}
+		if err := r.moveLegacyKeys(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	push, script := r.pushPosts, pushPostScript
+	if r.storage == StorageZSet {
+		push, script = r.zsetPushPosts, zsetPushScript
+	}
+
+	res, err := push(ctx, records, limit)
+	if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+		// script cache was flushed (e.g. redis restarted)
+		if err := script.Load(ctx, r.db).Err(); err != nil {
+			return nil, err
+		}
+		res, err = push(ctx, records, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// pushPosts pushes records to the existing lists in a single pipeline,
+// records of each list are pushed in the given order.
+func (r repo) pushPosts(ctx context.Context, records map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error) {
+	pipe := r.db.Pipeline()
+
+	cmds := make(map[xid.ID][]*redis.Cmd, len(records))
+	for userID, posts := range records {
+		cmds[userID] = make([]*redis.Cmd, len(posts))
+		for i := range posts {
+			cmds[userID][i] = pushPostScript.EvalSha(ctx, pipe,
+				[]string{r.keys.home(userID), r.keys.homeMeta(userID)},
+				posts[i], limit,
+			)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	res := make(map[xid.ID][]bool, len(cmds))
+	for userID := range cmds {
+		res[userID] = make([]bool, len(cmds[userID]))
+		for i := range cmds[userID] {
+			pushed, err := cmds[userID][i].Bool()
+			if err != nil {
+				return nil, err
+			}
+			res[userID][i] = pushed
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+func (r repo) CelebrityGet(ctx context.Context, userID xid.ID) (bool, error) {
+	if err := r.moveLegacyKeys(ctx, []legacyKey{{
+		legacy: legacyCelebrityCheckKeyPrefix + userID.String(),
+		key:    r.keys.celebrityCheck(userID),
+	}}); err != nil {
+		return false, err
+	}
+
+	val, err := r.db.Get(ctx, r.keys.celebrityCheck(userID)).Bool()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, repoerr.ErrNotFound
+		}
+		return false, err
+	}
+
+	return val, nil
+}
+
+func (r repo) CelebritySet(ctx context.Context, userID xid.ID, isCelebrity bool, ttl time.Duration) error {
+	pipe := r.db.TxPipeline()
+
+	pipe.Set(ctx, r.keys.celebrityCheck(userID), isCelebrity, ttl)
+	if isCelebrity {
+		pipe.SAdd(ctx, r.keys.celebrities(), userID.String())
+	} else {
+		pipe.SRem(ctx, r.keys.celebrities(), userID.String())
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if r.legacyKeys {
+		// the legacy status must not override the new one
+		pipe := r.db.Pipeline()
+		pipe.Del(ctx, legacyCelebrityCheckKeyPrefix+userID.String())
+		pipe.SRem(ctx, legacyCelebritiesKey, userID.String())
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r repo) CelebrityFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	members := make([]any, len(userIDs))
+	for i := range userIDs {
+		members[i] = userIDs[i].String()
+	}
+
+	found, err := r.db.SMIsMember(ctx, r.keys.celebrities(), members...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.legacyKeys {
+		legacyFound, err := r.db.SMIsMember(ctx, legacyCelebritiesKey, members...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i := range legacyFound {
+			found[i] = found[i] || legacyFound[i]
+		}
+	}
+
+	res := make([]xid.ID, 0)
+	for i := range found {
+		if found[i] {
+			res = append(res, userIDs[i])
+		}
+	}
+
+	return res, nil
+}
+
+// The followed celebrities are the hash with the fields below.
+const (
+	followedCelebrityIDLen = 20 // length of xid in text form
+
+	followedCelebritiesIDs       = "ids"        // concatenated ids in text form
+	followedCelebritiesCheckedAt = "checked_at" // unix milliseconds
+)
+
+func (r repo) FollowedCelebritiesGet(ctx context.Context, userID xid.ID) (entity.FollowedCelebrities, error) {
+	fields, err := r.db.HGetAll(ctx, r.keys.followedCelebrities(userID)).Result()
+	if err != nil {
+		return entity.FollowedCelebrities{}, err
+	}
+	if len(fields) == 0 {
+		return entity.FollowedCelebrities{}, repoerr.ErrNotFound
+	}
+
+	var res entity.FollowedCelebrities
+	ids := fields[followedCelebritiesIDs]
+	if len(ids)%followedCelebrityIDLen != 0 {
+		return entity.FollowedCelebrities{}, errors.New("invalid followed celebrities ids")
+	}
+	res.IDs = make([]xid.ID, 0, len(ids)/followedCelebrityIDLen)
+	for i := 0; i < len(ids); i += followedCelebrityIDLen {
+		id, err := xid.FromString(ids[i : i+followedCelebrityIDLen])
+		if err != nil {
+			return entity.FollowedCelebrities{}, err
+		}
+		res.IDs = append(res.IDs, id)
+	}
+	if ms, err := strconv.ParseInt(fields[followedCelebritiesCheckedAt], 10, 64); err == nil && ms > 0 {
+		res.CheckedAt = time.UnixMilli(ms)
+	}
+
+	return res, nil
+}
+
+func (r repo) FollowedCelebritiesSet(ctx context.Context, userID xid.ID, celebrities entity.FollowedCelebrities, ttl time.Duration) error {
+	var ids strings.Builder
+	for i := range celebrities.IDs {
+		ids.WriteString(celebrities.IDs[i].String())
+	}
+	var checkedAt int64
+	if !celebrities.CheckedAt.IsZero() {
+		checkedAt = celebrities.CheckedAt.UnixMilli()
+	}
+
+	key := r.keys.followedCelebrities(userID)
+
+	pipe := r.db.TxPipeline()
+	pipe.HSet(ctx, key,
+		followedCelebritiesIDs, ids.String(),
+		followedCelebritiesCheckedAt, checkedAt,
+	)
+	pipe.Expire(ctx, key, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
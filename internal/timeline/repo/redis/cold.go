@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// writeCold queues replacing the cold copy of the home timeline with the records to the pipeline.
+// The cold copy is a list with the same metadata as the timeline, it outlives the timeline for coldTTL,
+// so the expired timeline may be caught up from it. The copy without the build time can't be
+// caught up, so it is removed.
+func (r repo) writeCold(ctx context.Context, pipe redis.Pipeliner, userID xid.ID, records []entity.Post, meta entity.TimelineMeta) {
+	if r.coldTTL == 0 {
+		return
+	}
+
+	key, metaKey := r.keys.homeCold(userID), r.keys.homeColdMeta(userID)
+	if meta.BuiltAt.IsZero() {
+		pipe.Del(ctx, key, metaKey)
+		return
+	}
+
+	writeList(ctx, pipe, key, records, r.coldTTL)
+	writeMeta(ctx, pipe, metaKey, len(records), builtMetaFields(meta), r.coldTTL)
+}
+
+// deleteColdPost removes the post and its reposts from the cold copy of the home timeline,
+// so they don't reappear in the caught up timeline after the tombstone expires.
+func (r repo) deleteColdPost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	if r.coldTTL == 0 {
+		return nil
+	}
+
+	return deletePostScript.Run(ctx, r.db,
+		[]string{r.keys.homeCold(userID), r.keys.homeColdMeta(userID)},
+		post,
+	).Err()
+}
+
+func (r repo) ColdListGet(ctx context.Context, userID xid.ID) ([]entity.Post, entity.TimelineMeta, error) {
+	pipe := r.db.Pipeline()
+
+	listCmd := pipe.LRange(ctx, r.keys.homeCold(userID), 0, -1)
+	metaCmd := pipe.HGetAll(ctx, r.keys.homeColdMeta(userID))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, entity.TimelineMeta{}, err
+	}
+
+	if len(metaCmd.Val()) == 0 {
+		return nil, entity.TimelineMeta{}, repoerr.ErrNotFound
+	}
+	meta, err := parseMeta(metaCmd.Val())
+	if err != nil {
+		return nil, entity.TimelineMeta{}, err
+	}
+	if meta.BuiltAt.IsZero() {
+		return nil, entity.TimelineMeta{}, repoerr.ErrNotFound
+	}
+
+	posts := make([]entity.Post, 0, len(listCmd.Val()))
+	if err := listCmd.ScanSlice(&posts); err != nil {
+		return nil, entity.TimelineMeta{}, err
+	}
+
+	return posts, meta, nil
+}
+
+func (r repo) ColdListDelete(ctx context.Context, userID xid.ID) error {
+	return r.db.Del(ctx, r.keys.homeCold(userID), r.keys.homeColdMeta(userID)).Err()
+}
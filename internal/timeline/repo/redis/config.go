@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Storage is the redis data structure of the home timelines.
+type Storage string
+
+const (
+	// StorageList stores timeline as a list ordered from newest to oldest record.
+	StorageList Storage = "list"
+	// StorageZSet stores timeline as a sorted set scored by xid time of the post,
+	// list timelines are converted to it lazily on access.
+	StorageZSet Storage = "zset"
+)
+
+type Config struct {
+	Storage Storage `env:"STORAGE" envDefault:"list"`
+	// KeyPrefix is the namespace of all repo keys, so they don't collide
+	// with other data in the same redis. The watch events and the cache invalidations
+	// channels with their keys are namespaced by it too.
+	KeyPrefix string `env:"KEY_PREFIX" envDefault:"tl"`
+	// LegacyKeys enables reading the keys written before the key namespace was introduced:
+	// they are moved to the namespaced ones on first access. It should be enabled only while
+	// the legacy keys are migrated or expired, since it costs an extra round trip on each access
+	// and an extra lookup on each tombstone and celebrity check.
+	LegacyKeys bool `env:"LEGACY_KEYS" envDefault:"false"`
+	// ColdTTL is how long the cold copy of the home timeline with its build metadata is kept
+	// after the timeline is written or read, so the expired timeline may be caught up from it
+	// instead of the rebuild from scratch. It should be greater than the timeline TTL.
+	// The cold copies, and so the catch-up of the expired timelines, are opt-in:
+	// zero (default) disables them, since the copy holds the same records as the timeline,
+	// so it doubles the memory of the live timelines and also keeps it for every user active within ColdTTL.
+	ColdTTL time.Duration `env:"COLD_TTL" envDefault:"0"`
+}
+
+func (cfg Config) validate() error {
+	switch cfg.Storage {
+	case StorageList, StorageZSet:
+	default:
+		return fmt.Errorf("unknown timeline storage: %q", cfg.Storage)
+	}
+
+	if cfg.KeyPrefix == "" {
+		return errors.New("empty key prefix")
+	}
+
+	if cfg.ColdTTL < 0 {
+		return errors.New("negative cold copy TTL")
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"strconv"
+
+	"github.com/rs/xid"
+)
+
+// schemaVersion is the version of the stored data schema embedded into all keys:
+// it is incremented on incompatible changes, so the new keys don't collide with the old ones.
+const schemaVersion = 2
+
+// keys builds the redis keys of the repo: {prefix}:{schema version}:{kind}:{{user id}}.
+// The user id is a hash tag, so all keys of the user are in the same cluster slot.
+type keys struct {
+	prefix string
+}
+
+func newKeys(prefix string) keys {
+	return keys{prefix: prefix + ":v" + strconv.Itoa(schemaVersion) + ":"}
+}
+
+// home is the home timeline list of the user.
+func (k keys) home(userID xid.ID) string {
+	return k.prefix + "home:{" + userID.String() + "}"
+}
+
+// homeZSet is the home timeline sorted set of the user.
+func (k keys) homeZSet(userID xid.ID) string {
+	return k.prefix + "zhome:{" + userID.String() + "}"
+}
+
+// homeMeta is the metadata of the home timeline of the user (see writeMeta),
+// it is shared by the list and the sorted set timelines.
+func (k keys) homeMeta(userID xid.ID) string {
+	return k.prefix + "meta:home:{" + userID.String() + "}"
+}
+
+// homeCold is the cold copy of the home timeline list of the user (see writeCold).
+func (k keys) homeCold(userID xid.ID) string {
+	return k.prefix + "cold:home:{" + userID.String() + "}"
+}
+
+// homeColdMeta is the metadata of the cold copy of the home timeline of the user.
+func (k keys) homeColdMeta(userID xid.ID) string {
+	return k.prefix + "meta:cold:home:{" + userID.String() + "}"
+}
+
+// user is the list of the recent posts of the author.
+func (k keys) user(userID xid.ID) string {
+	return k.prefix + "user:{" + userID.String() + "}"
+}
+
+// userMeta is the metadata of the recent posts list of the author.
+func (k keys) userMeta(userID xid.ID) string {
+	return k.prefix + "meta:user:{" + userID.String() + "}"
+}
+
+// celebrityCheck is the checked celebrity status of the user.
+func (k keys) celebrityCheck(userID xid.ID) string {
+	return k.prefix + "celebrity:{" + userID.String() + "}"
+}
+
+// followedCelebrities is the celebrities followed by the user (see FollowedCelebritiesSet).
+func (k keys) followedCelebrities(userID xid.ID) string {
+	return k.prefix + "celebrities:home:{" + userID.String() + "}"
+}
+
+// celebrities is the set of the users known as celebrities.
+func (k keys) celebrities() string {
+	return k.prefix + "celebrities"
+}
+
+// tombstones is the sorted set of the deleted posts scored by expiration unix time.
+func (k keys) tombstones() string {
+	return k.prefix + "tombstones"
+}
+
+// userTombstones is the sorted set of the deleted users scored by expiration unix time.
+func (k keys) userTombstones() string {
+	return k.prefix + "tombstones:users"
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+func Test_keys(t *testing.T) {
+	k := newKeys("tl")
+	userID := xid.New()
+	uid := userID.String()
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "home", key: k.home(userID), want: "tl:v2:home:{" + uid + "}"},
+		{name: "home sorted set", key: k.homeZSet(userID), want: "tl:v2:zhome:{" + uid + "}"},
+		{name: "home meta", key: k.homeMeta(userID), want: "tl:v2:meta:home:{" + uid + "}"},
+		{name: "home cold", key: k.homeCold(userID), want: "tl:v2:cold:home:{" + uid + "}"},
+		{name: "home cold meta", key: k.homeColdMeta(userID), want: "tl:v2:meta:cold:home:{" + uid + "}"},
+		{name: "user", key: k.user(userID), want: "tl:v2:user:{" + uid + "}"},
+		{name: "user meta", key: k.userMeta(userID), want: "tl:v2:meta:user:{" + uid + "}"},
+		{name: "celebrity check", key: k.celebrityCheck(userID), want: "tl:v2:celebrity:{" + uid + "}"},
+		{name: "followed celebrities", key: k.followedCelebrities(userID), want: "tl:v2:celebrities:home:{" + uid + "}"},
+		{name: "celebrities", key: k.celebrities(), want: "tl:v2:celebrities"},
+		{name: "tombstones", key: k.tombstones(), want: "tl:v2:tombstones"},
+		{name: "user tombstones", key: k.userTombstones(), want: "tl:v2:tombstones:users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key)
+		})
+	}
+
+	// the namespaces don't collide
+	assert.NotEqual(t, k.home(userID), newKeys("other").home(userID))
+}
+
+func Test_repo_LegacyKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	db := newTestDB(ctx, t)
+
+	r := repo{
+		db:     db,
+		keys:   newKeys("tl"),
+		logger: zerolog.New(os.Stdout),
+	}
+
+	userID, authorID := xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+	record1, err := post1.MarshalBinary()
+	require.NoError(t, err)
+	record2, err := post2.MarshalBinary()
+	require.NoError(t, err)
+
+	// the timeline and the recent posts written before the key namespace was introduced
+	require.NoError(t, db.RPush(ctx, userID.String(), record2, record1).Err())
+	require.NoError(t, db.Expire(ctx, userID.String(), time.Hour).Err())
+	require.NoError(t, db.RPush(ctx, legacyUserKeyPrefix+authorID.String(), record2, record1).Err())
+	require.NoError(t, db.Expire(ctx, legacyUserKeyPrefix+authorID.String(), time.Hour).Err())
+
+	// legacy keys are not read by default
+	_, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.ErrorIs(t, err, repoerr.ErrNotFound)
+
+	// legacy keys are moved on first access keeping their TTL
+	r.legacyKeys = true
+
+	resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post2, post1}, resp)
+
+	authorPosts, err := r.UserListGet(ctx, []xid.ID{authorID}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post2, post1}, authorPosts[authorID])
+
+	assert.Zero(t, db.Exists(ctx, userID.String(), legacyUserKeyPrefix+authorID.String()).Val())
+	assert.Positive(t, db.PTTL(ctx, r.keys.home(userID)).Val())
+	assert.Positive(t, db.PTTL(ctx, r.keys.user(authorID)).Val())
+
+	// the namespaced key is newer than the legacy one written by the instance not upgraded yet
+	require.NoError(t, db.RPush(ctx, userID.String(), record1).Err())
+
+	resp, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post2, post1}, resp)
+	assert.Zero(t, db.Exists(ctx, userID.String()).Val())
+}
@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+)
+
+// Legacy keys were written before the key namespace was introduced,
+// they are read only if legacy keys mode is enabled.
+const (
+	legacyZSetKeyPrefix           = "z:"
+	legacyUserKeyPrefix           = "user:"
+	legacyCelebrityCheckKeyPrefix = "celebrity:"
+	legacyCelebritiesKey          = "celebrities"
+	legacyTombstonesKey           = "tombstones"
+	legacyUserTombstonesKey       = "tombstones:users"
+)
+
+// legacyKey is the legacy key and the namespaced key it is moved to.
+type legacyKey struct {
+	legacy string
+	key    string
+	// sameSlot reports whether both keys are in the same cluster slot,
+	// so the legacy key may be renamed.
+	sameSlot bool
+}
+
+func (r repo) homeLegacyKeys(userID xid.ID) []legacyKey {
+	return []legacyKey{
+		// the legacy key is the user id, which is the hash tag of the namespaced key
+		{legacy: userID.String(), key: r.keys.home(userID), sameSlot: true},
+		{legacy: legacyZSetKeyPrefix + userID.String(), key: r.keys.homeZSet(userID)},
+	}
+}
+
+func (r repo) userLegacyKeys(userIDs ...xid.ID) []legacyKey {
+	res := make([]legacyKey, len(userIDs))
+	for i := range userIDs {
+		res[i] = legacyKey{
+			legacy: legacyUserKeyPrefix + userIDs[i].String(),
+			key:    r.keys.user(userIDs[i]),
+		}
+	}
+	return res
+}
+
+// moveLegacyKeys moves the existing legacy keys to the namespaced ones if legacy keys mode is enabled,
+// so the data written before the key namespace was introduced is migrated on first access.
+// The namespaced key is not replaced if it exists: it is newer.
+func (r repo) moveLegacyKeys(ctx context.Context, keys []legacyKey) error {
+	if !r.legacyKeys || len(keys) == 0 {
+		return nil
+	}
+
+	pipe := r.db.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i := range keys {
+		cmds[i] = pipe.Exists(ctx, keys[i].legacy)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	for i := range cmds {
+		if cmds[i].Val() == 0 {
+			continue
+		}
+		if err := r.moveLegacyKey(ctx, keys[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// takeLegacyKeyScript atomically dumps and removes the legacy key (KEYS[1]) and returns
+// its serialized value and TTL in milliseconds or nil if the key does not exist.
+// The key is removed at once, so no write of the instances that still use it is made
+// between the dump and the removal: the later writes find no key as if it is expired.
+var takeLegacyKeyScript = redis.NewScript(`
+local value = redis.call("DUMP", KEYS[1])
+if not value then
+	return false
+end
+local ttl = redis.call("PTTL", KEYS[1])
+redis.call("DEL", KEYS[1])
+return {value, ttl}
+`)
+
+// moveLegacyKey renames the legacy key if both keys are in the same cluster slot,
+// otherwise it takes the legacy key and restores it keeping its TTL
+// (the data is rebuilt as expired if the restore fails).
+func (r repo) moveLegacyKey(ctx context.Context, k legacyKey) error {
+	if k.sameSlot {
+		return r.renameLegacyKey(ctx, k)
+	}
+
+	res, err := takeLegacyKeyScript.Run(ctx, r.db, []string{k.legacy}).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// moved concurrently
+			return nil
+		}
+		return err
+	}
+	value, ok := res[0].(string)
+	if !ok {
+		return fmt.Errorf("invalid legacy key dump type: %T", res[0])
+	}
+	ttl, ok := res[1].(int64)
+	if !ok {
+		return fmt.Errorf("invalid legacy key ttl type: %T", res[1])
+	}
+
+	// zero TTL means no expiration for RESTORE
+	if err := r.db.Restore(ctx, k.key, time.Duration(max(ttl, 0))*time.Millisecond, value).Err(); err != nil &&
+		!redis.HasErrorPrefix(err, "BUSYKEY") {
+		return err
+	}
+
+	r.logger.Debug().
+		Str("key", k.key).
+		Msg("legacy key moved")
+
+	return nil
+}
+
+func (r repo) renameLegacyKey(ctx context.Context, k legacyKey) error {
+	renamed, err := r.db.RenameNX(ctx, k.legacy, k.key).Result()
+	if err != nil {
+		if redis.HasErrorPrefix(err, "no such key") {
+			// moved concurrently
+			return nil
+		}
+		return err
+	}
+	if !renamed {
+		// the namespaced key is newer
+		return r.db.Del(ctx, k.legacy).Err()
+	}
+
+	r.logger.Debug().
+		Str("key", k.key).
+		Msg("legacy key moved")
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// The timeline metadata is the hash with the fields below. The timeline exists while its metadata
+// exists, even if it has no records: redis removes the empty list or sorted set.
+// The scripts changing the records keep the count up to date.
+const (
+	metaBuiltAt = "built_at" // unix milliseconds
+	metaSchema  = "schema"
+	metaSource  = "source"
+	metaCount   = "count"
+)
+
+// writeMeta queues updating the timeline metadata to the pipeline: the schema version
+// and the count are always updated, the other fields only if given
+// (e.g. the rewritten timeline keeps its build time and source).
+func writeMeta(ctx context.Context, pipe redis.Pipeliner, key string, count int, fields []any, ttl time.Duration) {
+	values := append([]any{metaSchema, schemaVersion, metaCount, count}, fields...)
+
+	pipe.HSet(ctx, key, values...)
+	pipe.Expire(ctx, key, ttl)
+}
+
+// builtMetaFields returns the metadata fields of the built timeline.
+func builtMetaFields(meta entity.TimelineMeta) []any {
+	return []any{
+		metaBuiltAt, meta.BuiltAt.UnixMilli(),
+		metaSource, int(meta.Source),
+	}
+}
+
+func parseMeta(fields map[string]string) (entity.TimelineMeta, error) {
+	var (
+		meta entity.TimelineMeta
+		err  error
+	)
+	if v, ok := fields[metaBuiltAt]; ok {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return entity.TimelineMeta{}, err
+		}
+		meta.BuiltAt = time.UnixMilli(ms)
+	}
+	if v, ok := fields[metaSchema]; ok {
+		if meta.SchemaVersion, err = strconv.Atoi(v); err != nil {
+			return entity.TimelineMeta{}, err
+		}
+	}
+	if v, ok := fields[metaSource]; ok {
+		source, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return entity.TimelineMeta{}, err
+		}
+		meta.Source = entity.TimelineSource(source)
+	}
+	if v, ok := fields[metaCount]; ok {
+		if meta.Count, err = strconv.Atoi(v); err != nil {
+			return entity.TimelineMeta{}, err
+		}
+	}
+
+	return meta, nil
+}
+
+func (r repo) ListMeta(ctx context.Context, userID xid.ID) (entity.TimelineMeta, error) {
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return entity.TimelineMeta{}, err
+	}
+
+	pipe := r.db.Pipeline()
+
+	metaCmd := pipe.HGetAll(ctx, r.keys.homeMeta(userID))
+	// the timelines built before the metadata was introduced have none
+	existsCmd := pipe.Exists(ctx, r.keys.home(userID), r.keys.homeZSet(userID))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return entity.TimelineMeta{}, err
+	}
+
+	if len(metaCmd.Val()) == 0 {
+		if existsCmd.Val() == 0 {
+			return entity.TimelineMeta{}, repoerr.ErrNotFound
+		}
+		return entity.TimelineMeta{}, nil
+	}
+
+	return parseMeta(metaCmd.Val())
+}
+
+// isEmptyMarker reports whether the record is the marker of the existing but empty timeline:
+// the timelines built before the metadata was introduced end with it.
+func isEmptyMarker(post entity.Post) bool {
+	return post.PostID.IsZero() && post.AuthorID.IsZero()
+}
+
+// withoutEmptyMarker removes the marker of the existing but empty timeline from the posts.
+func withoutEmptyMarker(posts []entity.Post) []entity.Post {
+	return slices.DeleteFunc(posts, isEmptyMarker)
+}
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+const migrateTimeout = 5 * time.Second
+
+// hasOutdatedRecords reports whether any of the records is encoded in the older format.
+func hasOutdatedRecords(records []string) bool {
+	for i := range records {
+		if entity.IsOutdatedPostEncoding([]byte(records[i])) {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateList rewrites the records of the list encoded in older formats to the current one in background.
+// Lists are migrated online on read, so the not read ones just expire in the old format.
+// The records are replaced in place, so the list keeps its TTL, and the migration
+// is skipped if the list is changed concurrently: it is retried on the next read.
+func (r repo) migrateList(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	var migrated int
+	err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+		records, err := tx.LRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i := range records {
+				record, ok, err := migrateRecord(records[i])
+				if err != nil {
+					return err
+				}
+				if ok {
+					pipe.LSet(ctx, key, int64(i), record)
+					migrated++
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+	r.logMigration(key, migrated, err)
+}
+
+// migrateZSet is the same as migrateList for the sorted set timeline.
+func (r repo) migrateZSet(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	var migrated int
+	err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+		members, err := tx.ZRangeWithScores(ctx, key, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i := range members {
+				member, ok := members[i].Member.(string)
+				if !ok || len(member) < zsetPostIDLen {
+					continue
+				}
+				record, ok, err := migrateRecord(member[zsetPostIDLen:])
+				if err != nil {
+					return err
+				}
+				if ok {
+					pipe.ZRem(ctx, key, member)
+					pipe.ZAdd(ctx, key, redis.Z{
+						Score:  members[i].Score,
+						Member: member[:zsetPostIDLen] + record,
+					})
+					migrated++
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+	r.logMigration(key, migrated, err)
+}
+
+// migrateRecord returns the record encoded in the current format and true
+// if it is encoded in the older one.
+func migrateRecord(record string) (string, bool, error) {
+	if !entity.IsOutdatedPostEncoding([]byte(record)) {
+		return record, false, nil
+	}
+
+	var post entity.Post
+	if err := post.UnmarshalBinary([]byte(record)); err != nil {
+		return "", false, err
+	}
+	b, err := post.MarshalBinary()
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(b), true, nil
+}
+
+func (r repo) logMigration(key string, migrated int, err error) {
+	switch {
+	case errors.Is(err, redis.TxFailedErr):
+		// changed concurrently, will be migrated on the next read
+	case err != nil:
+		r.logger.Error().
+			Err(err).
+			Str("key", key).
+			Msg("failed to migrate records")
+	case migrated != 0:
+		r.logger.Debug().
+			Str("key", key).
+			Int("records", migrated).
+			Msg("records migrated to the current format")
+	}
+}
@@ -4,35 +4,68 @@ import (
 	"context"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/xid"
 
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
 )
 
-func (r repo) ExistedListPushPost(ctx context.Context, userID xid.ID, record entity.Post, limit int64) error {
-	pipe := r.db.Pipeline()
+func (r repo) ExistedListPushPost(ctx context.Context, userID xid.ID, record entity.Post, limit int64) (bool, error) {
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return false, err
+	}
+
+	if r.storage == StorageZSet {
+		return r.zsetPushPost(ctx, userID, record, limit)
+	}
+
+	pushed, err := pushPostScript.Run(ctx, r.db,
+		[]string{r.keys.home(userID), r.keys.homeMeta(userID)},
+		record, limit,
+	).Bool()
+	if err != nil {
+		return false, err
+	}
+
+	return pushed, nil
+}
+
+func (r repo) ListSet(ctx context.Context, userID xid.ID, records []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error {
+	// the legacy keys are replaced too
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return err
+	}
 
-	pipe.LPushX(ctx, userID.String(), record)
-	pipe.LTrim(ctx, userID.String(), 0, limit+1)
+	pipe := r.db.TxPipeline()
+
+	if r.storage == StorageZSet {
+		if err := writeZSet(ctx, pipe, r.keys.homeZSet(userID), records, ttl); err != nil {
+			return err
+		}
+	} else {
+		writeList(ctx, pipe, r.keys.home(userID), records, ttl)
+	}
+	writeMeta(ctx, pipe, r.keys.homeMeta(userID), len(records), builtMetaFields(meta), ttl)
+	r.writeCold(ctx, pipe, userID, records, meta)
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return err
 	}
 
+	if r.storage == StorageZSet {
+		// the list timeline is replaced by the sorted set one
+		return r.db.Del(ctx, r.keys.home(userID)).Err()
+	}
+
 	return nil
 }
 
-func (r repo) ListSet(ctx context.Context, userID xid.ID, records []entity.Post, ttl time.Duration) error {
-	pipe := r.db.Pipeline()
-
-	pipe.LTrim(ctx, userID.String(), 0, 0)
-	pipe.LPush(ctx, userID.String(), emptyPost)
+// setList replaces the list with the records and its metadata with the build one.
+func (r repo) setList(ctx context.Context, key, metaKey string, records []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error {
+	pipe := r.db.TxPipeline()
 
-	// TODO: perf: rewrite it for batch insert
-	for i := range records {
-		pipe.LPush(ctx, userID.String(), records[i])
-	}
-	pipe.Expire(ctx, userID.String(), ttl)
+	writeList(ctx, pipe, key, records, ttl)
+	writeMeta(ctx, pipe, metaKey, len(records), builtMetaFields(meta), ttl)
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return err
@@ -41,11 +74,58 @@ func (r repo) ListSet(ctx context.Context, userID xid.ID, records []entity.Post,
 	return nil
 }
 
+// writeList queues replacing the list with the records to the pipeline,
+// the metadata is written separately (see writeMeta).
+func writeList(ctx context.Context, pipe redis.Pipeliner, key string, records []entity.Post, ttl time.Duration) {
+	pipe.Del(ctx, key)
+	if len(records) == 0 {
+		return
+	}
+
+	values := make([]any, len(records))
+	for i := range records {
+		values[i] = records[i]
+	}
+
+	pipe.RPush(ctx, key, values...)
+	pipe.Expire(ctx, key, ttl)
+}
+
 func (r repo) ExistedListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error {
-	cmd := r.db.LRem(ctx, userID.String(), -1, post)
-	return cmd.Err()
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	var err error
+	if r.storage == StorageZSet {
+		err = r.zsetDeletePost(ctx, userID, post)
+	} else {
+		err = deletePostScript.Run(ctx, r.db,
+			[]string{r.keys.home(userID), r.keys.homeMeta(userID)},
+			post,
+		).Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.deleteColdPost(ctx, userID, post)
 }
 
 func (r repo) ExistedListDelete(ctx context.Context, userID xid.ID) error {
-	return r.db.Del(ctx, userID.String()).Err()
+	// the legacy keys are removed too
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	// the deleted timeline must not be caught up
+	if err := r.ColdListDelete(ctx, userID); err != nil {
+		return err
+	}
+
+	if r.storage == StorageZSet {
+		return r.zsetDelete(ctx, userID)
+	}
+
+	return r.db.Del(ctx, r.keys.home(userID), r.keys.homeMeta(userID)).Err()
 }
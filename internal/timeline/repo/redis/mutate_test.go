@@ -54,14 +54,235 @@ func Test_repo_ExistedListDeletePost(t *testing.T) {
 				PostID:   xid.New(),
 				AuthorID: xid.New(),
 			},
-		}, time.Hour)
+		}, entity.TimelineMeta{}, time.Hour)
 	require.NoError(t, err)
 
 	err = r.ExistedListDeletePost(ctx, userID, post1)
 	require.NoError(t, err)
 
-	resp, err := r.ListGet(ctx, userID, 0, 10, nil)
+	resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
 	require.NoError(t, err)
 
 	assert.Len(t, resp, 1)
 }
+
+func Test_repo_ListUpdate_ConcurrentPush(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	redisContainer, err := rc.Run(ctx, "redis:6")
+	require.NoError(t, err)
+	defer redisContainer.Terminate(context.TODO()) //nolint:errcheck
+
+	url, err := redisContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	url, found := strings.CutPrefix(url, "redis://")
+	require.True(t, found)
+
+	db, err := redis.NewDB(ctx, redis.Config{Addrs: []string{url}})
+	require.NoError(t, err)
+
+	for _, storage := range []Storage{StorageList, StorageZSet} {
+		t.Run(string(storage), func(t *testing.T) {
+			r := repo{
+				db:      db,
+				storage: storage,
+				logger:  zerolog.New(os.Stdout),
+			}
+
+			userID := xid.New()
+			targetUserID := xid.New()
+
+			post1 := entity.Post{PostID: xid.New(), AuthorID: targetUserID}
+			post2 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+			post3 := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+
+			err = r.ListSet(ctx, userID, []entity.Post{post2, post1}, entity.TimelineMeta{}, time.Hour)
+			require.NoError(t, err)
+
+			var calls int
+			err = r.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+				calls++
+				if calls == 1 {
+					// push between read and write of the timeline
+					pushed, err := r.ExistedListPushPost(ctx, userID, post3, 10)
+					require.NoError(t, err)
+					require.True(t, pushed)
+				}
+
+				res := make([]entity.Post, 0, len(posts))
+				for _, p := range posts {
+					if p.AuthorID != targetUserID {
+						res = append(res, p)
+					}
+				}
+				return res
+			}, time.Hour)
+			require.NoError(t, err)
+
+			resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, calls)
+			assert.Equal(t, []entity.Post{post3, post2}, resp)
+		})
+	}
+}
+
+func Test_repo_ListGet_OldPostRepost(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	// the repost id is kept by the version 2 records only
+	require.NoError(t, entity.SetPostEncoding(entity.PostEncodingV2))
+	t.Cleanup(func() { _ = entity.SetPostEncoding(entity.PostEncodingV1) })
+
+	redisContainer, err := rc.Run(ctx, "redis:6")
+	require.NoError(t, err)
+	defer redisContainer.Terminate(context.TODO()) //nolint:errcheck
+
+	url, err := redisContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	url, found := strings.CutPrefix(url, "redis://")
+	require.True(t, found)
+
+	db, err := redis.NewDB(ctx, redis.Config{Addrs: []string{url}})
+	require.NoError(t, err)
+
+	for _, storage := range []Storage{StorageList, StorageZSet} {
+		t.Run(string(storage), func(t *testing.T) {
+			r := repo{
+				db:      db,
+				storage: storage,
+				logger:  zerolog.New(os.Stdout),
+			}
+
+			userID := xid.New()
+
+			post1 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: xid.New()}
+			post2 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Minute)), AuthorID: xid.New()}
+			// the original post is older than the whole timeline
+			repost := entity.Post{
+				PostID:     xid.NewWithTime(time.Now().Add(-2 * time.Hour)),
+				AuthorID:   xid.New(),
+				IsRepost:   true,
+				RepostedBy: post2.AuthorID,
+				RepostID:   xid.New(),
+			}
+
+			err = r.ListSet(ctx, userID, []entity.Post{post2, post1}, entity.TimelineMeta{}, time.Hour)
+			require.NoError(t, err)
+
+			pushed, err := r.ExistedListPushPost(ctx, userID, repost, 10)
+			require.NoError(t, err)
+			require.True(t, pushed)
+
+			// the post is shown once
+			pushed, err = r.ExistedListPushPost(ctx, userID, entity.Post{PostID: repost.PostID, AuthorID: repost.AuthorID}, 10)
+			require.NoError(t, err)
+			require.False(t, pushed)
+
+			// paging by cursor ends
+			var (
+				res    []entity.Post
+				cursor = xid.NilID()
+			)
+			for range 10 {
+				page, err := r.ListGet(ctx, userID, cursor, 0, 1, nil)
+				require.NoError(t, err)
+				if len(page) == 0 {
+					break
+				}
+				res = append(res, page...)
+				cursor = page[0].SortID()
+			}
+			assert.Equal(t, []entity.Post{repost, post2, post1}, res)
+
+			newer, count, err := r.ListGetNewer(ctx, userID, post2.PostID, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Equal(t, []entity.Post{repost}, newer)
+		})
+	}
+}
+
+// newTestDB returns the client of redis run in a container for the test.
+func newTestDB(ctx context.Context, t *testing.T) redis.DB {
+	t.Helper()
+
+	redisContainer, err := rc.Run(ctx, "redis:6")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = redisContainer.Terminate(context.TODO()) })
+
+	url, err := redisContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	url, found := strings.CutPrefix(url, "redis://")
+	require.True(t, found)
+
+	db, err := redis.NewDB(ctx, redis.Config{Addrs: []string{url}})
+	require.NoError(t, err)
+
+	return db
+}
+
+func Test_repo_ExistedListPushPost_OutOfOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	db := newTestDB(ctx, t)
+
+	for _, storage := range []Storage{StorageList, StorageZSet} {
+		t.Run(string(storage), func(t *testing.T) {
+			r := repo{
+				db:      db,
+				storage: storage,
+				keys:    newKeys("tl"),
+				logger:  zerolog.New(os.Stdout),
+			}
+
+			userID := xid.New()
+			posts := make([]entity.Post, 5)
+			for i := range posts {
+				// the posts of different seconds are ordered by sorted set score
+				posts[i] = entity.Post{PostID: xid.NewWithTime(time.Now().Add(time.Duration(i-5) * time.Minute)), AuthorID: xid.New()}
+			}
+
+			// not existed timeline is not created
+			pushed, err := r.ExistedListPushPost(ctx, userID, posts[0], 10)
+			require.NoError(t, err)
+			assert.False(t, pushed)
+
+			err = r.ListSet(ctx, userID, []entity.Post{posts[3], posts[0]}, entity.TimelineMeta{}, time.Hour)
+			require.NoError(t, err)
+
+			// delayed posts are inserted at their chronological position
+			for _, post := range []entity.Post{posts[1], posts[4], posts[2]} {
+				pushed, err = r.ExistedListPushPost(ctx, userID, post, 10)
+				require.NoError(t, err)
+				assert.True(t, pushed)
+			}
+
+			// replayed post is ignored
+			pushed, err = r.ExistedListPushPost(ctx, userID, posts[1], 10)
+			require.NoError(t, err)
+			assert.False(t, pushed)
+
+			resp, err := r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []entity.Post{posts[4], posts[3], posts[2], posts[1], posts[0]}, resp)
+
+			// the oldest records are trimmed to limit
+			post := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+			pushed, err = r.ExistedListPushPost(ctx, userID, post, 3)
+			require.NoError(t, err)
+			assert.True(t, pushed)
+
+			resp, err = r.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []entity.Post{post, posts[4], posts[3]}, resp)
+		})
+	}
+}
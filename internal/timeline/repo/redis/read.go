@@ -2,8 +2,11 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/xid"
 
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
@@ -12,52 +15,245 @@ import (
 
 const setExpireTimeout = 5 * time.Second
 
-func (r repo) ListGet(ctx context.Context, userID xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error) {
+// rangeAfterScript returns up to limit records of the list (KEYS[1]) ordered after the cursor (sort id) skipping offset
+// of them or nil if list does not exist (neither it nor its metadata, KEYS[2]). It scans the list
+// from the head by chunks, so the pages near the head don't require a full LRANGE.
+var rangeAfterScript = redis.NewScript(postIDLua + `
+local n = redis.call("LLEN", KEYS[1])
+if n == 0 then
+	if redis.call("EXISTS", KEYS[2]) == 0 then
+		return false
+	end
+	return {}
+end
+local cursor, offset, limit = ARGV[1], tonumber(ARGV[2]), tonumber(ARGV[3])
+local chunk = 100
+for i = 0, n - 1, chunk do
+	local records = redis.call("LRANGE", KEYS[1], i, i + chunk - 1)
+	for j = 1, #records do
+		if sort_id(records[j]) < cursor then
+			local start = i + j - 1 + offset
+			return redis.call("LRANGE", KEYS[1], start, start + limit - 1)
+		end
+	end
+end
+return {}
+`)
+
+// newerScript returns the count of records of the list (KEYS[1]) ordered before since (sort id)
+// and up to limit newest of them or nil if list does not exist (neither it nor its metadata, KEYS[2]).
+// It stops scanning the list at the first not newer record.
+var newerScript = redis.NewScript(postIDLua + `
+local n = redis.call("LLEN", KEYS[1])
+if n == 0 then
+	if redis.call("EXISTS", KEYS[2]) == 0 then
+		return false
+	end
+	return {0, {}}
+end
+local since, limit = ARGV[1], tonumber(ARGV[2])
+local chunk = 50
+local count = n
+local i = 0
+while i < n and count == n do
+	local records = redis.call("LRANGE", KEYS[1], i, i + chunk - 1)
+	for j = 1, #records do
+		if sort_id(records[j]) <= since then
+			count = i + j - 1
+			break
+		end
+	end
+	i = i + chunk
+end
+if limit == 0 or count == 0 then
+	return {count, {}}
+end
+return {count, redis.call("LRANGE", KEYS[1], 0, math.min(count, limit) - 1)}
+`)
+
+func (r repo) ListGet(ctx context.Context, userID, cursor xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error) {
+	key, metaKey := r.keys.home(userID), r.keys.homeMeta(userID)
+	if r.storage == StorageZSet {
+		key = r.keys.homeZSet(userID)
+	}
+
 	expfn := func() {
 		ctx, cancel := context.WithTimeout(context.Background(), setExpireTimeout)
 		defer cancel()
 
-		if cmd := r.db.Expire(ctx, userID.String(), *ttl); cmd.Err() != nil {
+		pipe := r.db.Pipeline()
+		pipe.Expire(ctx, key, *ttl)
+		pipe.Expire(ctx, metaKey, *ttl)
+		if r.coldTTL != 0 {
+			pipe.Expire(ctx, r.keys.homeCold(userID), r.coldTTL)
+			pipe.Expire(ctx, r.keys.homeColdMeta(userID), r.coldTTL)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
 			r.logger.Error().
-				Err(cmd.Err()).
+				Err(err).
 				Str("user_id", userID.String()).
 				Msg("failed to set expire")
 			return
 		}
 	}
 
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return nil, err
+	}
+
+	var (
+		records     []string
+		err         error
+		fromReplica bool
+	)
+	if cursor.IsNil() && repoerr.IsReplicaRead(ctx) {
+		records, err = r.replicaRange(ctx, userID, offset, limit)
+		// the timeline may be not replicated or converted yet, so fall back to the primary
+		fromReplica = err == nil
+	}
+	switch {
+	case fromReplica:
+	case r.storage == StorageZSet:
+		records, err = r.zsetRange(ctx, userID, cursor, offset, limit)
+	case cursor.IsNil():
+		records, err = r.listRange(ctx, key, metaKey, offset, limit)
+	default:
+		records, err = rangeAfterScript.Run(ctx, r.db,
+			[]string{key, metaKey},
+			cursor.String(), offset, limit,
+		).StringSlice()
+		if errors.Is(err, redis.Nil) {
+			err = repoerr.ErrNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl != nil {
+		go expfn()
+	}
+	if hasOutdatedRecords(records) {
+		if r.storage == StorageZSet {
+			go r.migrateZSet(key)
+		} else {
+			go r.migrateList(key)
+		}
+	}
+
+	res := make([]entity.Post, len(records))
+	for i := range records {
+		if err := res[i].UnmarshalBinary([]byte(records[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	return withoutEmptyMarker(res), nil
+}
+
+// listRange returns limit records of the list skipping offset of them
+// or ErrNotFound if list does not exist (neither it nor its metadata).
+func (r repo) listRange(ctx context.Context, key, metaKey string, offset, limit int) ([]string, error) {
 	pipe := r.db.Pipeline()
 
-	lenCmd := pipe.LLen(ctx, userID.String())
-	listCmd := pipe.LRange(ctx, userID.String(), int64(offset), int64(offset+limit-1))
+	existsCmd := pipe.Exists(ctx, key, metaKey)
+	listCmd := pipe.LRange(ctx, key, int64(offset), int64(offset+limit-1))
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return nil, err
 	}
 
-	if len(listCmd.Val()) != 0 {
-		res := make([]entity.Post, 0, len(listCmd.Val()))
-		if err := listCmd.ScanSlice(&res); err != nil {
-			return nil, err
-		}
+	if existsCmd.Val() == 0 {
+		return nil, repoerr.ErrNotFound
+	}
 
-		if ttl != nil {
-			go expfn()
-		}
+	return listCmd.Val(), nil
+}
 
-		// remove empty elements
-		if v := res[len(res)-1]; v.PostID.IsZero() && v.AuthorID.IsZero() {
-			res = res[:len(res)-1]
-		}
+// replicaRange is the same as listRange for the list or the sorted set timeline,
+// but it uses the replica client, so the plain read commands may be served by replicas.
+func (r repo) replicaRange(ctx context.Context, userID xid.ID, offset, limit int) ([]string, error) {
+	pipe := r.db.Replica().Pipeline()
 
-		return res, nil
+	var (
+		existsCmd, listCmd *redis.IntCmd
+		rangeCmd           *redis.StringSliceCmd
+	)
+	if r.storage == StorageZSet {
+		key := r.keys.homeZSet(userID)
+		existsCmd = pipe.Exists(ctx, key, r.keys.homeMeta(userID))
+		listCmd = pipe.Exists(ctx, r.keys.home(userID))
+		rangeCmd = pipe.ZRevRange(ctx, key, int64(offset), int64(offset+limit-1))
+	} else {
+		key := r.keys.home(userID)
+		existsCmd = pipe.Exists(ctx, key, r.keys.homeMeta(userID))
+		rangeCmd = pipe.LRange(ctx, key, int64(offset), int64(offset+limit-1))
 	}
 
-	if lenCmd.Val() == 0 {
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	if existsCmd.Val() == 0 {
 		return nil, repoerr.ErrNotFound
 	}
+	if listCmd != nil && listCmd.Val() != 0 {
+		return nil, errNotConverted
+	}
+
+	records := rangeCmd.Val()
+	if r.storage == StorageZSet {
+		for i := range records {
+			records[i] = records[i][zsetPostIDLen:]
+		}
+	}
+
+	return records, nil
+}
+
+func (r repo) ListGetNewer(ctx context.Context, userID, sinceID xid.ID, limit int) ([]entity.Post, int, error) {
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		v   []any
+		err error
+	)
+	if r.storage == StorageZSet {
+		v, err = r.zsetNewer(ctx, userID, sinceID, limit)
+	} else {
+		v, err = newerScript.Run(ctx, r.db,
+			[]string{r.keys.home(userID), r.keys.homeMeta(userID)},
+			sinceID.String(), limit,
+		).Slice()
+	}
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, 0, repoerr.ErrNotFound
+		}
+		return nil, 0, err
+	}
 
-	go expfn()
+	count, ok := v[0].(int64)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid type assertion: want int64, got %T", v[0])
+	}
+	records, ok := v[1].([]any)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid type assertion: want []any, got %T", v[1])
+	}
+
+	res := make([]entity.Post, len(records))
+	for i := range records {
+		record, ok := records[i].(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid type assertion: want string, got %T", records[i])
+		}
+		if err := res[i].UnmarshalBinary([]byte(record)); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	return []entity.Post{}, nil
+	return res, int(count), nil
 }
@@ -1,30 +1,37 @@
 package redis
 
 import (
-	"github.com/rs/xid"
+	"time"
+
 	"github.com/rs/zerolog"
 
-	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
 	"github.com/Karzoug/meower-timeline-service/pkg/redis"
 )
 
-var emptyPost = entity.Post{
-	AuthorID: xid.NilID(),
-	PostID:   xid.NilID(),
-}
-
 type repo struct {
-	db     redis.DB
-	logger zerolog.Logger
+	db         redis.DB
+	storage    Storage
+	keys       keys
+	legacyKeys bool
+	coldTTL    time.Duration
+	logger     zerolog.Logger
 }
 
-func NewTimelineRepo(db redis.DB, logger zerolog.Logger) repo {
+func NewTimelineRepo(cfg Config, db redis.DB, logger zerolog.Logger) (repo, error) {
+	if err := cfg.validate(); err != nil {
+		return repo{}, err
+	}
+
 	logger = logger.With().
 		Str("component", "redis repo").
 		Logger()
 
 	return repo{
-		db:     db,
-		logger: logger,
-	}
+		db:         db,
+		storage:    cfg.Storage,
+		keys:       newKeys(cfg.KeyPrefix),
+		legacyKeys: cfg.LegacyKeys,
+		coldTTL:    cfg.ColdTTL,
+		logger:     logger,
+	}, nil
 }
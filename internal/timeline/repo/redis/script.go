@@ -0,0 +1,125 @@
+package redis
+
+import "github.com/redis/go-redis/v9"
+
+// postIDLua returns post id and sort id (see entity.Post SortID) of the record in text form,
+// see entity.Post MarshalBinary for the record formats.
+// The raw ids are encoded to text to compare the records of all formats the same way:
+// string comparison of redis lua depends on the collation locale, but it keeps the order of xid text.
+const postIDLua = `
+local alphabet = "0123456789abcdefghijklmnopqrstuv"
+local function encode_id(raw)
+	local out = {}
+	local acc, bits = 0, 0
+	for i = 1, 12 do
+		acc = acc * 256 + string.byte(raw, i)
+		bits = bits + 8
+		while bits >= 5 do
+			bits = bits - 5
+			local v = math.floor(acc / 2 ^ bits)
+			out[#out + 1] = string.sub(alphabet, v + 1, v + 1)
+			acc = acc - v * 2 ^ bits
+		end
+	end
+	local v = acc * 2 ^ (5 - bits)
+	out[#out + 1] = string.sub(alphabet, v + 1, v + 1)
+	return table.concat(out)
+end
+local function post_id(record)
+	local version = string.byte(record, 1)
+	if version == 2 then
+		return encode_id(string.sub(record, 14, 25))
+	end
+	if version == 1 then
+		return string.sub(record, 22, 41)
+	end
+	return string.sub(record, 21, 40)
+end
+-- sort_id returns the id the record is ordered by: the repost id of the version 2 repost
+-- having it (it follows the reposted by id if any) and the post id otherwise
+local function sort_id(record)
+	if string.byte(record, 1) == 2 then
+		local flags = string.byte(record, 26)
+		if math.floor(flags / 4) % 2 == 1 then
+			local offset = 27
+			if math.floor(flags / 2) % 2 == 1 then
+				offset = offset + 12
+			end
+			return encode_id(string.sub(record, offset, offset + 11))
+		end
+	end
+	return post_id(record)
+end
+`
+
+// pushPostScript inserts the record into the existing list (KEYS[1]) ordered by sort id (newest first)
+// at its chronological position, so delayed or replayed records don't break the order.
+// The list exists while its metadata (KEYS[2]) exists: the empty one is created with the metadata TTL.
+// It does nothing if the list already contains the record with the same post id: the records
+// are never ordered before their post, so it may only be among the records not older than the post.
+// It scans the list from the head by chunks, so the usual insert near the head doesn't require a full LRANGE.
+// It trims the list to limit records and returns 1 if the record was inserted.
+var pushPostScript = redis.NewScript(postIDLua + `
+local has_meta = redis.call("EXISTS", KEYS[2]) == 1
+local n = redis.call("LLEN", KEYS[1])
+if n == 0 and not has_meta then
+	return 0
+end
+local id, sid = post_id(ARGV[1]), sort_id(ARGV[1])
+local chunk = 100
+local pivot = nil
+local done = false
+for i = 0, n - 1, chunk do
+	local records = redis.call("LRANGE", KEYS[1], i, i + chunk - 1)
+	for j = 1, #records do
+		local rsid = sort_id(records[j])
+		if rsid < sid and not pivot then
+			pivot = records[j]
+		end
+		if rsid < id then
+			done = true
+			break
+		end
+		if post_id(records[j]) == id then
+			return 0
+		end
+	end
+	if done then
+		break
+	end
+end
+if pivot then
+	redis.call("LINSERT", KEYS[1], "BEFORE", pivot, ARGV[1])
+else
+	redis.call("RPUSH", KEYS[1], ARGV[1])
+end
+if n == 0 then
+	local ttl = redis.call("PTTL", KEYS[2])
+	if ttl > 0 then
+		redis.call("PEXPIRE", KEYS[1], ttl)
+	end
+end
+redis.call("LTRIM", KEYS[1], 0, tonumber(ARGV[2]) - 1)
+if has_meta then
+	redis.call("HSET", KEYS[2], "count", redis.call("LLEN", KEYS[1]))
+end
+return 1
+`)
+
+// deletePostScript removes all records of the list (KEYS[1]) with the same post id as the record
+// (the post itself and its reposts in any format), updates the count of its metadata (KEYS[2])
+// and returns the number of removed records.
+var deletePostScript = redis.NewScript(postIDLua + `
+local id = post_id(ARGV[1])
+local records = redis.call("LRANGE", KEYS[1], 0, -1)
+local removed = 0
+for i = 1, #records do
+	if post_id(records[i]) == id then
+		removed = removed + redis.call("LREM", KEYS[1], 0, records[i])
+	end
+end
+if removed > 0 and redis.call("EXISTS", KEYS[2]) == 1 then
+	redis.call("HSET", KEYS[2], "count", redis.call("LLEN", KEYS[1]))
+end
+return removed
+`)
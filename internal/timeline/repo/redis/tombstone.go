@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+)
+
+func (r repo) TombstoneAdd(ctx context.Context, postID xid.ID, ttl time.Duration) error {
+	return r.addTombstone(ctx, r.keys.tombstones(), postID, ttl)
+}
+
+func (r repo) TombstoneFilter(ctx context.Context, postIDs []xid.ID) ([]xid.ID, error) {
+	return r.filterTombstones(ctx, r.keys.tombstones(), legacyTombstonesKey, postIDs)
+}
+
+func (r repo) UserTombstoneAdd(ctx context.Context, userID xid.ID, ttl time.Duration) error {
+	return r.addTombstone(ctx, r.keys.userTombstones(), userID, ttl)
+}
+
+func (r repo) UserTombstoneFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	return r.filterTombstones(ctx, r.keys.userTombstones(), legacyUserTombstonesKey, userIDs)
+}
+
+func (r repo) addTombstone(ctx context.Context, key string, id xid.ID, ttl time.Duration) error {
+	now := time.Now()
+
+	// the same tombstone is added by the task of each follower, so the repeated adds
+	// don't modify the hot key: they are not replicated and don't extend the tombstone
+	pipe := r.db.Pipeline()
+
+	// remove expired tombstones, so the expired one doesn't block the new one
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(now.Unix(), 10))
+	pipe.ZAddNX(ctx, key, redis.Z{
+		Score:  float64(now.Add(ttl).Unix()),
+		Member: id.String(),
+	})
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// filterTombstones returns ids with not expired tombstones in the key
+// or in the legacy key if legacy keys mode is enabled.
+func (r repo) filterTombstones(ctx context.Context, key, legacy string, ids []xid.ID) ([]xid.ID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	members := make([]string, len(ids))
+	for i := range ids {
+		members[i] = ids[i].String()
+	}
+
+	scores, err := r.db.ZMScore(ctx, key, members...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.legacyKeys {
+		legacyScores, err := r.db.ZMScore(ctx, legacy, members...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i := range legacyScores {
+			scores[i] = max(scores[i], legacyScores[i])
+		}
+	}
+
+	now := float64(time.Now().Unix())
+	res := make([]xid.ID, 0)
+	for i := range scores {
+		// zero score means that the id has no tombstone
+		if scores[i] >= now {
+			res = append(res, ids[i])
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// maxUpdateRetries is the number of optimistic update attempts of the timeline
+// changed concurrently before giving up.
+const maxUpdateRetries = 10
+
+var errTooManyUpdateRetries = errors.New("timeline is changed concurrently too often")
+
+func (r repo) ListUpdate(ctx context.Context, userID xid.ID, fn func([]entity.Post) []entity.Post, ttl time.Duration) error {
+	if err := r.moveLegacyKeys(ctx, r.homeLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	key, metaKey, listKey := r.keys.home(userID), r.keys.homeMeta(userID), r.keys.home(userID)
+	if r.storage == StorageZSet {
+		key = r.keys.homeZSet(userID)
+	}
+
+	txf := func(tx *redis.Tx) error {
+		pipe := tx.Pipeline()
+		var recordsCmd *redis.StringSliceCmd
+		if r.storage == StorageZSet {
+			recordsCmd = pipe.ZRevRange(ctx, key, 0, -1)
+		} else {
+			recordsCmd = pipe.LRange(ctx, key, 0, -1)
+		}
+		metaCmd := pipe.HGetAll(ctx, metaKey)
+		listCmd := pipe.Exists(ctx, listKey)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+
+		records := recordsCmd.Val()
+		if len(records) == 0 {
+			if r.storage == StorageZSet && listCmd.Val() != 0 {
+				return errNotConverted
+			}
+			if len(metaCmd.Val()) == 0 {
+				return repoerr.ErrNotFound
+			}
+		}
+		if r.storage == StorageZSet {
+			for i := range records {
+				records[i] = records[i][zsetPostIDLen:]
+			}
+		}
+
+		posts := make([]entity.Post, len(records))
+		for i := range records {
+			if err := posts[i].UnmarshalBinary([]byte(records[i])); err != nil {
+				return err
+			}
+		}
+
+		meta, err := parseMeta(metaCmd.Val())
+		if err != nil {
+			return err
+		}
+
+		res := fn(withoutEmptyMarker(posts))
+
+		// the transaction fails if the timeline is changed after the read,
+		// the rewritten timeline keeps its build metadata
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if r.storage == StorageZSet {
+				if err := writeZSet(ctx, pipe, key, res, ttl); err != nil {
+					return err
+				}
+			} else {
+				writeList(ctx, pipe, key, res, ttl)
+			}
+			writeMeta(ctx, pipe, metaKey, len(res), nil, ttl)
+			r.writeCold(ctx, pipe, userID, res, meta)
+			return nil
+		})
+		return err
+	}
+
+	for range maxUpdateRetries {
+		err := r.db.Watch(ctx, txf, key, metaKey, listKey)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if errors.Is(err, errNotConverted) {
+			if err := r.zsetMigrate(ctx, userID); err != nil {
+				return err
+			}
+			continue
+		}
+		return err
+	}
+
+	return errTooManyUpdateRetries
+}
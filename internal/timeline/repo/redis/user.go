@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func (r repo) UserListGet(ctx context.Context, userIDs []xid.ID, limit int) (map[xid.ID][]entity.Post, error) {
+	if err := r.moveLegacyKeys(ctx, r.userLegacyKeys(userIDs...)); err != nil {
+		return nil, err
+	}
+
+	pipe := r.db.Pipeline()
+
+	var (
+		cmds       = make([]*redis.StringSliceCmd, len(userIDs))
+		existsCmds = make([]*redis.IntCmd, len(userIDs))
+	)
+	for i := range userIDs {
+		cmds[i] = pipe.LRange(ctx, r.keys.user(userIDs[i]), 0, int64(limit))
+		existsCmds[i] = pipe.Exists(ctx, r.keys.userMeta(userIDs[i]))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make(map[xid.ID][]entity.Post, len(userIDs))
+	for i := range cmds {
+		if len(cmds[i].Val()) == 0 && existsCmds[i].Val() == 0 {
+			// not found user timeline in cache
+			continue
+		}
+
+		if hasOutdatedRecords(cmds[i].Val()) {
+			go r.migrateList(r.keys.user(userIDs[i]))
+		}
+
+		posts := make([]entity.Post, 0, len(cmds[i].Val()))
+		if err := cmds[i].ScanSlice(&posts); err != nil {
+			return nil, err
+		}
+
+		posts = withoutEmptyMarker(posts)
+		res[userIDs[i]] = posts[:min(limit, len(posts))]
+	}
+
+	return res, nil
+}
+
+func (r repo) UserListSet(ctx context.Context, userID xid.ID, records []entity.Post, ttl time.Duration) error {
+	// the legacy key is replaced too
+	if err := r.moveLegacyKeys(ctx, r.userLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	return r.setList(ctx, r.keys.user(userID), r.keys.userMeta(userID), records, entity.TimelineMeta{
+		BuiltAt: time.Now(),
+		Source:  entity.TimelineSourceScratch,
+	}, ttl)
+}
+
+func (r repo) ExistedUserListPushPost(ctx context.Context, userID xid.ID, record entity.Post, limit int64) error {
+	if err := r.moveLegacyKeys(ctx, r.userLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	return pushPostScript.Run(ctx, r.db,
+		[]string{r.keys.user(userID), r.keys.userMeta(userID)},
+		record, limit,
+	).Err()
+}
+
+func (r repo) ExistedUserListDeletePost(ctx context.Context, userID xid.ID, record entity.Post) error {
+	if err := r.moveLegacyKeys(ctx, r.userLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	return deletePostScript.Run(ctx, r.db,
+		[]string{r.keys.user(userID), r.keys.userMeta(userID)},
+		record,
+	).Err()
+}
+
+func (r repo) ExistedUserListDelete(ctx context.Context, userID xid.ID) error {
+	// the legacy key is removed too
+	if err := r.moveLegacyKeys(ctx, r.userLegacyKeys(userID)); err != nil {
+		return err
+	}
+
+	return r.db.Del(ctx, r.keys.user(userID), r.keys.userMeta(userID)).Err()
+}
@@ -0,0 +1,424 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// Sorted set timeline members are the sort id (see entity.Post SortID) in text form followed
+// by the record, they are scored by xid time of the sort id, so the members of the same second
+// are ordered by sort id too. The marker of the existing timeline (see isEmptyMarker) has zero score.
+const zsetPostIDLen = 20
+
+var errNotConverted = errors.New("list timeline is not converted to sorted set yet")
+
+// zsetLua contains helpers for the sorted set timeline members.
+// The scripts are called with the keys of the sorted set (KEYS[1]), its metadata (KEYS[2])
+// and, if needed, the list timeline (KEYS[3]).
+const zsetLua = postIDLua + `
+local function member_id(member)
+	return string.sub(member, 1, 20)
+end
+local function member_post_id(member)
+	return post_id(string.sub(member, 21))
+end
+local function records(members)
+	for i = 1, #members do
+		members[i] = string.sub(members[i], 21)
+	end
+	return members
+end
+local function update_count()
+	if redis.call("EXISTS", KEYS[2]) == 1 then
+		redis.call("HSET", KEYS[2], "count", redis.call("ZCARD", KEYS[1]))
+	end
+end
+-- the sorted set is absent if the list timeline is not converted yet or the timeline
+-- does not exist or it is empty: only the last one is known to exist without conversion
+local function exists_empty()
+	return redis.call("EXISTS", KEYS[3]) == 0 and redis.call("EXISTS", KEYS[2]) == 1
+end
+`
+
+// zsetPushScript adds the member with the score to the existing sorted set if it does not
+// contain the member with the same post id yet and trims the set to limit newest members.
+// The members are never scored before their post, so only the members scored not before
+// the post (ARGV[4]) are checked.
+// The empty timeline set is created with the metadata TTL.
+// It returns 1 if the member was added, 0 if it is a duplicate and -1 if the set does not exist.
+var zsetPushScript = redis.NewScript(zsetLua + `
+local created = false
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	if not exists_empty() then
+		return -1
+	end
+	created = true
+end
+local id = member_post_id(ARGV[1])
+local newer = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[4], "+inf")
+for i = 1, #newer do
+	if member_post_id(newer[i]) == id then
+		return 0
+	end
+end
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+if created then
+	local ttl = redis.call("PTTL", KEYS[2])
+	if ttl > 0 then
+		redis.call("PEXPIRE", KEYS[1], ttl)
+	end
+end
+local n = redis.call("ZCARD", KEYS[1])
+local limit = tonumber(ARGV[3])
+if n > limit then
+	redis.call("ZREMRANGEBYRANK", KEYS[1], 0, n - limit - 1)
+end
+update_count()
+return 1
+`)
+
+// zsetRangeAfterScript returns up to limit records ordered after the cursor (all if cursor is empty)
+// skipping offset of them or nil if the set does not exist.
+var zsetRangeAfterScript = redis.NewScript(zsetLua + `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	if exists_empty() then
+		return {}
+	end
+	return false
+end
+local limit = tonumber(ARGV[4])
+if limit <= 0 then
+	return {}
+end
+local start = 0
+if ARGV[1] ~= "" then
+	start = redis.call("ZCOUNT", KEYS[1], "(" .. ARGV[2], "+inf")
+	local same = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[2], ARGV[2])
+	for i = 1, #same do
+		if member_id(same[i]) >= ARGV[1] then
+			start = start + 1
+		end
+	end
+end
+start = start + tonumber(ARGV[3])
+return records(redis.call("ZREVRANGE", KEYS[1], start, start + limit - 1))
+`)
+
+// zsetNewerScript returns the count of records ordered before since
+// and up to limit newest of them or nil if the set does not exist.
+var zsetNewerScript = redis.NewScript(zsetLua + `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	if exists_empty() then
+		return {0, {}}
+	end
+	return false
+end
+local count = redis.call("ZCOUNT", KEYS[1], "(" .. ARGV[2], "+inf")
+local same = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[2], ARGV[2])
+for i = 1, #same do
+	if member_id(same[i]) > ARGV[1] then
+		count = count + 1
+	end
+end
+local limit = tonumber(ARGV[3])
+if limit == 0 or count == 0 then
+	return {count, {}}
+end
+return {count, records(redis.call("ZREVRANGE", KEYS[1], 0, math.min(count, limit) - 1))}
+`)
+
+// zsetDeletePostScript removes all members with the post id (the post itself and its reposts)
+// scored not before the post, updates the count of the metadata and returns the number of removed members.
+var zsetDeletePostScript = redis.NewScript(zsetLua + `
+local newer = redis.call("ZRANGEBYSCORE", KEYS[1], ARGV[2], "+inf")
+local removed = 0
+for i = 1, #newer do
+	if member_post_id(newer[i]) == ARGV[1] then
+		removed = removed + redis.call("ZREM", KEYS[1], newer[i])
+	end
+end
+if removed > 0 then
+	update_count()
+end
+return removed
+`)
+
+// zsetScriptKeys returns the keys the sorted set scripts are called with, see zsetLua.
+func (r repo) zsetScriptKeys(userID xid.ID) []string {
+	return []string{r.keys.homeZSet(userID), r.keys.homeMeta(userID), r.keys.home(userID)}
+}
+
+// zsetScore returns the score of the record with the sort id in the sorted set timeline.
+func zsetScore(sortID xid.ID) string {
+	return strconv.FormatInt(sortID.Time().Unix(), 10)
+}
+
+func zsetMember(post entity.Post) (redis.Z, error) {
+	record, err := post.MarshalBinary()
+	if err != nil {
+		return redis.Z{}, err
+	}
+
+	return redis.Z{
+		Score:  float64(post.SortID().Time().Unix()),
+		Member: post.SortID().String() + string(record),
+	}, nil
+}
+
+func (r repo) zsetRange(ctx context.Context, userID, cursor xid.ID, offset, limit int) ([]string, error) {
+	var cursorArg string
+	if !cursor.IsNil() {
+		cursorArg = cursor.String()
+	}
+
+	run := func() ([]string, error) {
+		return zsetRangeAfterScript.Run(ctx, r.db,
+			r.zsetScriptKeys(userID),
+			cursorArg, zsetScore(cursor), offset, limit,
+		).StringSlice()
+	}
+
+	records, err := run()
+	if errors.Is(err, redis.Nil) {
+		if err := r.zsetMigrate(ctx, userID); err != nil {
+			return nil, err
+		}
+		records, err = run()
+	}
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, repoerr.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r repo) zsetNewer(ctx context.Context, userID, sinceID xid.ID, limit int) ([]any, error) {
+	run := func() ([]any, error) {
+		return zsetNewerScript.Run(ctx, r.db,
+			r.zsetScriptKeys(userID),
+			sinceID.String(), zsetScore(sinceID), limit,
+		).Slice()
+	}
+
+	v, err := run()
+	if errors.Is(err, redis.Nil) {
+		if err := r.zsetMigrate(ctx, userID); err != nil {
+			return nil, err
+		}
+		v, err = run()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r repo) zsetPushPost(ctx context.Context, userID xid.ID, record entity.Post, limit int64) (bool, error) {
+	member, err := zsetMember(record)
+	if err != nil {
+		return false, err
+	}
+
+	run := func() (int, error) {
+		return zsetPushScript.Run(ctx, r.db,
+			r.zsetScriptKeys(userID),
+			member.Member, zsetScore(record.SortID()), limit, zsetScore(record.PostID),
+		).Int()
+	}
+
+	res, err := run()
+	if err == nil && res == -1 {
+		if err := r.zsetMigrate(ctx, userID); err != nil {
+			if errors.Is(err, repoerr.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		res, err = run()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return res == 1, nil
+}
+
+// zsetPushPosts pushes records to the existing sorted sets in a single pipeline,
+// records of each set are pushed in the given order.
+func (r repo) zsetPushPosts(ctx context.Context, records map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error) {
+	pipe := r.db.Pipeline()
+
+	cmds := make(map[xid.ID][]*redis.Cmd, len(records))
+	for userID, posts := range records {
+		cmds[userID] = make([]*redis.Cmd, len(posts))
+		for i := range posts {
+			member, err := zsetMember(posts[i])
+			if err != nil {
+				return nil, err
+			}
+			cmds[userID][i] = zsetPushScript.EvalSha(ctx, pipe,
+				r.zsetScriptKeys(userID),
+				member.Member, zsetScore(posts[i].SortID()), limit, zsetScore(posts[i].PostID),
+			)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	res := make(map[xid.ID][]bool, len(cmds))
+	for userID := range cmds {
+		res[userID] = make([]bool, len(cmds[userID]))
+		for i := range cmds[userID] {
+			v, err := cmds[userID][i].Int()
+			if err != nil {
+				return nil, err
+			}
+			if v == -1 {
+				// the timeline may be not converted yet
+				for j := i; j < len(cmds[userID]); j++ {
+					pushed, err := r.zsetPushPost(ctx, userID, records[userID][j], limit)
+					if err != nil {
+						return nil, err
+					}
+					res[userID][j] = pushed
+				}
+				break
+			}
+			res[userID][i] = v == 1
+		}
+	}
+
+	return res, nil
+}
+
+// writeZSet queues replacing the sorted set with the records to the pipeline,
+// the metadata is written separately (see writeMeta).
+func writeZSet(ctx context.Context, pipe redis.Pipeliner, key string, records []entity.Post, ttl time.Duration) error {
+	members := make([]redis.Z, len(records))
+	for i := range records {
+		member, err := zsetMember(records[i])
+		if err != nil {
+			return err
+		}
+		members[i] = member
+	}
+
+	pipe.Del(ctx, key)
+	if len(members) != 0 {
+		pipe.ZAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, ttl)
+	}
+
+	return nil
+}
+
+func (r repo) zsetDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	if err := zsetDeletePostScript.Run(ctx, r.db,
+		r.zsetScriptKeys(userID),
+		post.PostID.String(), zsetScore(post.PostID),
+	).Err(); err != nil {
+		return err
+	}
+
+	// the list timeline may be not converted yet
+	return deletePostScript.Run(ctx, r.db,
+		[]string{r.keys.home(userID), r.keys.homeMeta(userID)},
+		post,
+	).Err()
+}
+
+func (r repo) zsetDelete(ctx context.Context, userID xid.ID) error {
+	if err := r.db.Del(ctx, r.keys.homeZSet(userID), r.keys.homeMeta(userID)).Err(); err != nil {
+		return err
+	}
+
+	return r.db.Del(ctx, r.keys.home(userID)).Err()
+}
+
+// zsetMigrate converts the list timeline of the user to the sorted set one
+// keeping its TTL or returns ErrNotFound if there is no list timeline.
+// Both keys are watched, so the records pushed or updated concurrently are not lost:
+// the conversion is retried with them.
+func (r repo) zsetMigrate(ctx context.Context, userID xid.ID) error {
+	listKey, key := r.keys.home(userID), r.keys.homeZSet(userID)
+
+	var converted int
+	txf := func(tx *redis.Tx) error {
+		converted = 0
+
+		pipe := tx.Pipeline()
+		listCmd := pipe.LRange(ctx, listKey, 0, -1)
+		ttlCmd := pipe.PTTL(ctx, listKey)
+		existsCmd := pipe.Exists(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		if len(listCmd.Val()) == 0 {
+			if existsCmd.Val() == 1 {
+				// converted concurrently
+				return nil
+			}
+			return repoerr.ErrNotFound
+		}
+
+		members := make([]redis.Z, len(listCmd.Val()))
+		for i, record := range listCmd.Val() {
+			var post entity.Post
+			if err := post.UnmarshalBinary([]byte(record)); err != nil {
+				return err
+			}
+			// the records are converted to the current format too
+			member, err := zsetMember(post)
+			if err != nil {
+				return err
+			}
+			members[i] = member
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			pipe.ZAdd(ctx, key, members...)
+			if ttl := ttlCmd.Val(); ttl > 0 {
+				pipe.PExpire(ctx, key, ttl)
+			}
+			pipe.Del(ctx, listKey)
+			return nil
+		})
+		converted = len(members)
+		return err
+	}
+
+	for range maxUpdateRetries {
+		err := r.db.Watch(ctx, txf, listKey, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if converted > 0 {
+			r.logger.Debug().
+				Str("user_id", userID.String()).
+				Int("records", converted).
+				Msg("list timeline converted to sorted set")
+		}
+		return nil
+	}
+
+	return errTooManyUpdateRetries
+}
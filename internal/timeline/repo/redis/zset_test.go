@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+func Test_repo_ZSet_ConvertList(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	db := newTestDB(ctx, t)
+
+	listRepo := repo{
+		db:      db,
+		storage: StorageList,
+		keys:    newKeys("tl"),
+		logger:  zerolog.New(os.Stdout),
+	}
+	zsetRepo := listRepo
+	zsetRepo.storage = StorageZSet
+
+	userID := xid.New()
+	posts := make([]entity.Post, 4)
+	for i := range posts {
+		posts[i] = entity.Post{PostID: xid.NewWithTime(time.Now().Add(time.Duration(i-4) * time.Minute)), AuthorID: xid.New()}
+	}
+
+	_, err := zsetRepo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.ErrorIs(t, err, repoerr.ErrNotFound)
+
+	// the timeline written before the storage was switched
+	err = listRepo.ListSet(ctx, userID, []entity.Post{posts[2], posts[0]}, entity.TimelineMeta{}, time.Hour)
+	require.NoError(t, err)
+
+	// the list is converted on the first access
+	resp, err := zsetRepo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{posts[2], posts[0]}, resp)
+
+	assert.Zero(t, db.Exists(ctx, zsetRepo.keys.home(userID)).Val())
+	assert.Equal(t, "zset", db.Type(ctx, zsetRepo.keys.homeZSet(userID)).Val())
+	assert.Positive(t, db.PTTL(ctx, zsetRepo.keys.homeZSet(userID)).Val())
+
+	// the converted timeline is updated as sorted set
+	pushed, err := zsetRepo.ExistedListPushPost(ctx, userID, posts[1], 10)
+	require.NoError(t, err)
+	assert.True(t, pushed)
+	pushed, err = zsetRepo.ExistedListPushPost(ctx, userID, posts[3], 10)
+	require.NoError(t, err)
+	assert.True(t, pushed)
+	require.NoError(t, zsetRepo.ExistedListDeletePost(ctx, userID, posts[2]))
+
+	resp, err = zsetRepo.ListGet(ctx, userID, posts[3].PostID, 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{posts[1], posts[0]}, resp)
+
+	newer, count, err := zsetRepo.ListGetNewer(ctx, userID, posts[0].PostID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []entity.Post{posts[3]}, newer)
+
+	// the timeline converted on push keeps the pushed post
+	otherID := xid.New()
+	err = listRepo.ListSet(ctx, otherID, []entity.Post{posts[0]}, entity.TimelineMeta{}, time.Hour)
+	require.NoError(t, err)
+
+	pushed, err = zsetRepo.ExistedListPushPost(ctx, otherID, posts[1], 10)
+	require.NoError(t, err)
+	assert.True(t, pushed)
+
+	resp, err = zsetRepo.ListGet(ctx, otherID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{posts[1], posts[0]}, resp)
+}
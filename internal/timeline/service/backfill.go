@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// backfillPosts returns recent posts of the user followings (without excludedUserID).
+// It is used when the timeline runs thin after removing posts of the excluded user,
+// so their posts are never returned even if they are reposted by other followings.
+func (ts TimelineService) backfillPosts(ctx context.Context, followingIDs []xid.ID, excludedUserID xid.ID) ([]entity.Post, error) {
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"BackfillTimeline")
+	defer span.End()
+
+	// celebrity posts are not stored in timeline, they are merged at read time
+	followingIDs, err := ts.withoutCelebrities(ctx, followingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	backfill, err := ts.usersPosts(ctx, followingIDs, ts.cfg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutUserPosts(backfill, excludedUserID), nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Karzoug/meower-common-go/ucerr"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// PushTimelinePosts pushes posts to timelines of several users at once,
+// it is the batch analog of PushTimelinePost. Posts of each user
+// are pushed in the given order.
+func (ts TimelineService) PushTimelinePosts(ctx context.Context, posts map[xid.ID][]entity.Post) error {
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"PushTimelinePosts")
+	defer span.End()
+
+	// the same post is usually fanned out to many users
+	authorPosts := make(map[xid.ID]entity.Post)
+	for _, pp := range posts {
+		for i := range pp {
+			authorPosts[pp[i].PostID] = pp[i]
+		}
+	}
+
+	celebrityPosts := make(map[xid.ID]bool, len(authorPosts))
+	for postID, post := range authorPosts {
+		isCelebrity, err := ts.fanOutPost(ctx, post)
+		if err != nil {
+			return ucerr.NewInternalError(err)
+		}
+		celebrityPosts[postID] = isCelebrity
+	}
+
+	toPush := make(map[xid.ID][]entity.Post, len(posts))
+	for userID, pp := range posts {
+		for i := range pp {
+			if celebrityPosts[pp[i].PostID] {
+				// celebrity posts are merged into timeline at read time
+				ts.publish(ctx, userID, entity.EventTypePostInserted, pp[i])
+				continue
+			}
+			toPush[userID] = append(toPush[userID], pp[i])
+		}
+	}
+
+	pushed, err := ts.repo.ExistedListsPushPosts(ctx, toPush, int64(ts.cfg.Limit))
+	if err != nil {
+		return ucerr.NewInternalError(err)
+	}
+
+	for userID, pp := range toPush {
+		for i := range pp {
+			if pushed[userID][i] {
+				ts.publish(ctx, userID, entity.EventTypePostInserted, pp[i])
+			}
+		}
+	}
+
+	return nil
+}
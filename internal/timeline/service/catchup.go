@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// catchUpTimeline builds the expired timeline from its cold copy and the newest posts of the followings,
+// so only a few posts published after the copy was built are requested instead of the whole timeline.
+// It reports false if the timeline can't be caught up: there is no cold copy or the requested posts
+// may not cover all posts published after the copy was built.
+func (ts TimelineService) catchUpTimeline(ctx context.Context, userID xid.ID, followingIDs []xid.ID) ([]entity.Post, bool) {
+	// the cached recent posts of each author are limited too
+	limit := min(ts.cfg.CatchUpLimit, ts.cfg.UserTimelineLimit)
+	if limit <= 0 {
+		return nil, false
+	}
+
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"CatchUpTimeline")
+	defer span.End()
+
+	cold, meta, err := ts.repo.ColdListGet(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, repoerr.ErrNotFound) {
+			span.RecordError(err)
+			ts.logger.Warn().
+				Err(err).
+				Str("user_id", userID.String()).
+				Msg("failed to get cold timeline")
+		}
+		return nil, false
+	}
+
+	posts, err := ts.usersPosts(ctx, followingIDs, limit)
+	if err != nil {
+		span.RecordError(err)
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to get posts to catch up timeline")
+		return nil, false
+	}
+
+	// xid time has seconds precision, so the posts of the build second may be in the copy too:
+	// the duplicates are removed by merge
+	since := meta.BuiltAt.Truncate(time.Second)
+	if len(posts) == limit && !posts[len(posts)-1].PostID.Time().Before(since) {
+		// there may be the gap between the requested posts and the copy
+		span.AddEvent("too many posts to catch up timeline")
+		return nil, false
+	}
+
+	// the copy may contain posts of the users unfollowed, muted or turned into celebrities since then
+	following := make(map[xid.ID]struct{}, len(followingIDs))
+	for i := range followingIDs {
+		following[followingIDs[i]] = struct{}{}
+	}
+	cold = slices.DeleteFunc(cold, func(p entity.Post) bool {
+		_, byAuthor := following[p.AuthorID]
+		_, byReposter := following[p.RepostedBy]
+		return !byAuthor && !(p.IsRepost && byReposter)
+	})
+
+	return mergePosts(posts, cold, ts.cfg.Limit), true
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestTimelineService_CatchUpTimeline(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, author1, author2 := xid.New(), xid.New(), xid.New()
+	// xid time has seconds precision, so the posts are far from the build time
+	post1 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-2 * time.Hour)), AuthorID: author1}
+	post2 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: author2}
+	post3 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(time.Hour)), AuthorID: author1}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {author1, author2},
+	}}
+	posts := &fakePostService{posts: []entity.Post{post2, post1}}
+	ts := newTestService(t, relations, posts)
+	ts.cfg.TTL = 10 * time.Millisecond
+	ts.cfg.CatchUpLimit = 2
+
+	source := func() entity.TimelineSource {
+		_, meta, err := ts.repo.ColdListGet(ctx, userID)
+		require.NoError(t, err)
+		return meta.Source
+	}
+
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post2.PostID, post1.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceScratch, source())
+
+	// the expired timeline is caught up: the new post is merged into the cold copy
+	// without posts of the unfollowed user
+	time.Sleep(2 * ts.cfg.TTL)
+	posts.posts = []entity.Post{post3, post2, post1}
+	relations.followings[userID] = []xid.ID{author1}
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, post3))
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post3.PostID, post1.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceIncremental, source())
+
+	// all requested posts are newer than the cold copy -> timeline is built from scratch
+	time.Sleep(2 * ts.cfg.TTL)
+	ts.cfg.CatchUpLimit = 1
+	post4 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(2 * time.Hour)), AuthorID: author1}
+	posts.posts = []entity.Post{post4, post3, post2, post1}
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, post4))
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post4.PostID, post3.PostID, post1.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceScratch, source())
+}
+
+func TestTimelineService_CatchUpTimeline_LimitedLists(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, author1, author2 := xid.New(), xid.New(), xid.New()
+	newPost := func(authorID xid.ID, d time.Duration) entity.Post {
+		return entity.Post{PostID: xid.NewWithTime(time.Now().Add(d)), AuthorID: authorID}
+	}
+	a1, a2, a3 := newPost(author1, -5*time.Hour), newPost(author1, -4*time.Hour), newPost(author1, -3*time.Hour)
+	b1, a4 := newPost(author2, -2*time.Hour), newPost(author1, -time.Hour)
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {author1, author2},
+	}}
+	posts := &fakePostService{posts: []entity.Post{a4, b1, a3, a2, a1}}
+	ts := newTestService(t, relations, posts)
+	ts.cfg.TTL = 10 * time.Millisecond
+	ts.cfg.UserTimelineLimit = 3
+	ts.cfg.CatchUpLimit = 100
+
+	source := func() entity.TimelineSource {
+		_, meta, err := ts.repo.ColdListGet(ctx, userID)
+		require.NoError(t, err)
+		return meta.Source
+	}
+
+	// the recent posts of author1 are saved limited
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{a4.PostID, b1.PostID, a3.PostID, a2.PostID, a1.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceScratch, source())
+
+	// the new post is caught up from the limited list, since the list reaches the cold copy
+	time.Sleep(2 * ts.cfg.TTL)
+	a5 := newPost(author1, time.Hour)
+	posts.posts = append([]entity.Post{a5}, posts.posts...)
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, a5))
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{a5.PostID, a4.PostID, b1.PostID, a3.PostID, a2.PostID, a1.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceIncremental, source())
+
+	// the limited list holds only posts newer than the cold copy: there may be the gap
+	// between them, so the timeline is built from scratch, cut at the oldest post of the list
+	time.Sleep(2 * ts.cfg.TTL)
+	a6, a7, a8 := newPost(author1, 2*time.Hour), newPost(author1, 3*time.Hour), newPost(author1, 4*time.Hour)
+	for _, p := range []entity.Post{a6, a7, a8} {
+		posts.posts = append([]entity.Post{p}, posts.posts...)
+		require.NoError(t, ts.PushTimelinePost(ctx, userID, p))
+	}
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{a8.PostID, a7.PostID, a6.PostID}, postIDs(res))
+	assert.Equal(t, entity.TimelineSourceScratch, source())
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// isCelebrity reports whether the author has so many followers
+// that their posts are merged into timelines at read time instead of fan-out.
+func (ts TimelineService) isCelebrity(ctx context.Context, authorID xid.ID) (bool, error) {
+	if ts.cfg.CelebrityThreshold <= 0 {
+		return false, nil
+	}
+
+	isCelebrity, err := ts.repo.CelebrityGet(ctx, authorID)
+	if err == nil {
+		return isCelebrity, nil
+	}
+	if !errors.Is(err, repoerr.ErrNotFound) {
+		return false, err
+	}
+
+	// status is unknown or expired -> check it by followers count
+	r, err, _ := ts.syncGroup.Do("celebrity:"+authorID.String(), func() (any, error) {
+		followerIDs, err := ts.relationService.ListFollowerIDs(ctx, authorID)
+		if err != nil {
+			return nil, err
+		}
+
+		isCelebrity := len(followerIDs) >= ts.cfg.CelebrityThreshold
+		if err := ts.repo.CelebritySet(ctx, authorID, isCelebrity, ts.cfg.CelebrityCheckTTL); err != nil {
+			return nil, err
+		}
+
+		return isCelebrity, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	isCelebrity, ok := r.(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid type assertion: want bool, got %T", r)
+	}
+
+	return isCelebrity, nil
+}
+
+// withoutCelebrities returns user ids that are not known as celebrities.
+func (ts TimelineService) withoutCelebrities(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error) {
+	if ts.cfg.CelebrityThreshold <= 0 {
+		return userIDs, nil
+	}
+
+	celebrityIDs, err := ts.repo.CelebrityFilter(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(celebrityIDs) == 0 {
+		return userIDs, nil
+	}
+
+	res := make([]xid.ID, 0, len(userIDs)-len(celebrityIDs))
+	for i := range userIDs {
+		if !slices.Contains(celebrityIDs, userIDs[i]) {
+			res = append(res, userIDs[i])
+		}
+	}
+
+	return res, nil
+}
+
+// mergeCelebrityPosts merges recent posts of the celebrities older than cursor (if not nil)
+// into the timeline posts (fan-out on read).
+func (ts TimelineService) mergeCelebrityPosts(ctx context.Context, celebrityIDs []xid.ID, cursor xid.ID, posts []entity.Post, limit int) ([]entity.Post, error) {
+	celebrityPosts, err := ts.usersPosts(ctx, celebrityIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergePosts(posts, olderThan(celebrityPosts, cursor), limit), nil
+}
+
+// followedCelebrities returns the celebrities followed by the user: they are cached
+// for CelebrityFollowingsTTL, so the followings are not requested on each read.
+// The statuses of the followed celebrities are rechecked with them, so the former celebrities
+// are not merged forever: their posts are merged into the user timeline instead.
+func (ts TimelineService) followedCelebrities(ctx context.Context, userID xid.ID) ([]xid.ID, error) {
+	cached, err := ts.repo.FollowedCelebritiesGet(ctx, userID)
+	if err == nil && time.Since(cached.CheckedAt) < ts.cfg.CelebrityFollowingsTTL {
+		return cached.IDs, nil
+	}
+	if err != nil && !errors.Is(err, repoerr.ErrNotFound) {
+		return nil, err
+	}
+
+	checkedAt := time.Now()
+
+	followingIDs, err := ts.relationService.ListNotMutedFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	knownIDs, err := ts.repo.CelebrityFilter(ctx, followingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cached celebrities may be known as not celebrities already
+	candidateIDs := append(knownIDs, cached.IDs...)
+	slices.SortFunc(candidateIDs, xid.ID.Compare)
+	candidateIDs = slices.CompactFunc(candidateIDs, func(a, b xid.ID) bool { return a.Compare(b) == 0 })
+
+	var celebrityIDs, formerIDs []xid.ID
+	for _, id := range candidateIDs {
+		if !slices.Contains(followingIDs, id) {
+			continue
+		}
+		isCelebrity, err := ts.isCelebrity(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if isCelebrity {
+			celebrityIDs = append(celebrityIDs, id)
+		} else {
+			formerIDs = append(formerIDs, id)
+		}
+	}
+
+	if err := ts.mergeFormerCelebrityPosts(ctx, userID, formerIDs); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repo.FollowedCelebritiesSet(ctx, userID, entity.FollowedCelebrities{
+		IDs:       celebrityIDs,
+		CheckedAt: checkedAt,
+	}, ts.cfg.TTL); err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to set followed celebrities")
+	}
+
+	return celebrityIDs, nil
+}
+
+// mergeFormerCelebrityPosts merges recent posts of the former celebrities into the user timeline:
+// the posts published while they were celebrities were not pushed to the timeline.
+func (ts TimelineService) mergeFormerCelebrityPosts(ctx context.Context, userID xid.ID, formerIDs []xid.ID) error {
+	if len(formerIDs) == 0 {
+		return nil
+	}
+
+	var posts []entity.Post
+	for _, id := range formerIDs {
+		p, err := ts.userPosts(ctx, id)
+		if err != nil {
+			return err
+		}
+		posts = mergePosts(posts, p, ts.cfg.Limit)
+	}
+
+	// posts pushed concurrently are not lost
+	if err := ts.repo.ListUpdate(repoerr.WithPrimaryRead(ctx), userID, func(timeline []entity.Post) []entity.Post {
+		return mergePosts(timeline, posts, ts.cfg.Limit)
+	}, ts.cfg.TTL); err != nil && !errors.Is(err, repoerr.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// expireFollowedCelebrities makes the cached celebrities followed by the user to be rechecked
+// on the next read, since the followings of the user are changed.
+// The cached ones are kept to find the former celebrities among them.
+func (ts TimelineService) expireFollowedCelebrities(ctx context.Context, userID xid.ID) error {
+	if ts.cfg.CelebrityThreshold <= 0 {
+		return nil
+	}
+
+	cached, err := ts.repo.FollowedCelebritiesGet(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerr.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	cached.CheckedAt = time.Time{}
+	return ts.repo.FollowedCelebritiesSet(ctx, userID, cached, ts.cfg.TTL)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestTimelineService_GetTimeline_Celebrity(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, followerID, celebrityID := xid.New(), xid.New(), xid.New()
+	post := entity.Post{PostID: xid.New(), AuthorID: celebrityID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID:     {celebrityID},
+		followerID: {celebrityID},
+	}}
+	ts := newTestService(t, relations, &fakePostService{posts: []entity.Post{post}})
+	ts.cfg.CelebrityThreshold = 2
+	ts.cfg.CelebrityCheckTTL = 10 * time.Millisecond
+	ts.cfg.CelebrityFollowingsTTL = 10 * time.Millisecond
+
+	// the celebrity post is not pushed, but merged at read time
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, post))
+
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post.PostID}, postIDs(res))
+
+	cached, err := ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Empty(t, cached)
+
+	// the former celebrity post is merged into the timeline
+	relations.followings[followerID] = nil
+	time.Sleep(2 * ts.cfg.CelebrityFollowingsTTL)
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post.PostID}, postIDs(res))
+
+	cached, err = ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post.PostID}, postIDs(cached))
+
+	celebrityIDs, err := ts.repo.CelebrityFilter(ctx, []xid.ID{celebrityID})
+	require.NoError(t, err)
+	assert.Empty(t, celebrityIDs)
+}
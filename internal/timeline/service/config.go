@@ -7,6 +7,30 @@ type Config struct {
 	Limit int `env:"LIMIT,notEmpty" envDefault:"1000"`
 	// TTL of timeline records in cache.
 	TTL time.Duration `env:"TTL,notEmpty" envDefault:"72h"`
+	// MinSize is the number of timeline records below which the timeline is backfilled
+	// from followings after removing posts of the user. Zero disables backfill.
+	MinSize int `env:"MIN_SIZE" envDefault:"100"`
+	// TombstoneTTL is how long the deleted post is filtered out of all timelines at read time,
+	// it should be not less than TTL.
+	TombstoneTTL time.Duration `env:"TOMBSTONE_TTL,notEmpty" envDefault:"72h"`
+	// CatchUpLimit is the number of the newest posts of followings requested to catch up
+	// the expired timeline from its cold copy: if all of them are newer than the copy,
+	// the timeline is built from scratch. It is capped by UserTimelineLimit, zero disables catch-up.
+	// It has no effect until the cold copies are enabled in the repo (REPO_COLD_TTL).
+	CatchUpLimit int `env:"CATCH_UP_LIMIT" envDefault:"100"`
 	// BuildTimeout  is timeout for build timeline from scratch.
 	BuildTimeout time.Duration `env:"BUILD_TIMEOUT,notEmpty" envDefault:"180s"`
+	// UserTimelineLimit is the limit of recent posts of the author in cache.
+	UserTimelineLimit int `env:"USER_TIMELINE_LIMIT,notEmpty" envDefault:"200"`
+	// UserTimelineTTL is TTL of recent posts of the author in cache.
+	UserTimelineTTL time.Duration `env:"USER_TIMELINE_TTL,notEmpty" envDefault:"24h"`
+	// CelebrityThreshold is the number of followers starting from which the author is a celebrity:
+	// their posts are not pushed to followers timelines but merged into them at read time.
+	// Zero disables hybrid mode.
+	CelebrityThreshold int `env:"CELEBRITY_THRESHOLD" envDefault:"0"`
+	// CelebrityCheckTTL is how long the checked celebrity status of the author is cached.
+	CelebrityCheckTTL time.Duration `env:"CELEBRITY_CHECK_TTL,notEmpty" envDefault:"24h"`
+	// CelebrityFollowingsTTL is how long the celebrities followed by the user are cached
+	// for merging their posts at read time: the new celebrities are merged after it.
+	CelebrityFollowingsTTL time.Duration `env:"CELEBRITY_FOLLOWINGS_TTL,notEmpty" envDefault:"10m"`
 }
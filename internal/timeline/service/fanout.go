@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// fanoutTTL is how long the instance remembers the fanned out post:
+// the tasks of the post for all followers are usually sent at once.
+const fanoutTTL = time.Minute
+
+// fanOutPost does the author level work for the post pushed to the followers timelines
+// and reports whether the author is a celebrity. The pipeline sends a task per follower,
+// but the recent posts list of the author is updated and the celebrity status is checked
+// by the first task of the post on the instance only.
+func (ts TimelineService) fanOutPost(ctx context.Context, post entity.Post) (bool, error) {
+	if isCelebrity, ok := ts.fanouts.get(post.PostID); ok {
+		return isCelebrity, nil
+	}
+
+	r, err, _ := ts.syncGroup.Do("fanout:"+post.PostID.String(), func() (any, error) {
+		// the post may be fanned out by the concurrent task
+		if isCelebrity, ok := ts.fanouts.get(post.PostID); ok {
+			return isCelebrity, nil
+		}
+
+		if err := ts.repo.ExistedUserListPushPost(ctx, post.AuthorID, post, int64(ts.cfg.UserTimelineLimit)); err != nil {
+			return nil, err
+		}
+		isCelebrity, err := ts.isCelebrity(ctx, post.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.fanouts.add(post.PostID, isCelebrity)
+
+		return isCelebrity, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	isCelebrity, ok := r.(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid type assertion: want bool, got %T", r)
+	}
+
+	return isCelebrity, nil
+}
+
+// fanouts is the set of the recently fanned out posts with the celebrity status of their authors.
+type fanouts struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	posts map[xid.ID]fanout
+	// sweepAt is the size of posts at which the expired posts are removed
+	sweepAt int
+}
+
+type fanout struct {
+	isCelebrity bool
+	expiresAt   time.Time
+}
+
+const minFanoutsSweepSize = 1024
+
+func newFanouts(ttl time.Duration) *fanouts {
+	return &fanouts{
+		ttl:     ttl,
+		now:     time.Now,
+		posts:   make(map[xid.ID]fanout),
+		sweepAt: minFanoutsSweepSize,
+	}
+}
+
+func (f *fanouts) get(postID xid.ID) (isCelebrity, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.posts[postID]
+	if !ok || !f.now().Before(p.expiresAt) {
+		return false, false
+	}
+	return p.isCelebrity, true
+}
+
+func (f *fanouts) add(postID xid.ID, isCelebrity bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.posts[postID] = fanout{
+		isCelebrity: isCelebrity,
+		expiresAt:   now.Add(f.ttl),
+	}
+
+	if len(f.posts) < f.sweepAt {
+		return
+	}
+	for id, p := range f.posts {
+		if !now.Before(p.expiresAt) {
+			delete(f.posts, id)
+		}
+	}
+	// the set is swept again when it doubles, so the sweeps take amortized constant time
+	f.sweepAt = max(minFanoutsSweepSize, 2*len(f.posts))
+}
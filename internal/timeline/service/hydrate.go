@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Karzoug/meower-common-go/ucerr"
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+const removePostsTimeout = 5 * time.Second
+
+// GetFullTimeline returns the page of the user timeline with the content of the posts
+// and the token of the next page (empty if there are no subsequent pages).
+// Deleted posts are omitted, so the page may be shorter than the limit.
+func (ts TimelineService) GetFullTimeline(ctx context.Context, reqUserID, userID xid.ID, pgn PaginationOptions) ([]entity.FullPost, string, error) {
+	posts, nextPageToken, err := ts.GetTimeline(ctx, reqUserID, userID, pgn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"HydratePosts")
+	defer span.End()
+
+	res, err := ts.hydratePosts(ctx, reqUserID, userID, posts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return res, nextPageToken, nil
+}
+
+// hydratePosts joins posts with their content from post service
+// and removes deleted posts from the user timeline in background.
+func (ts TimelineService) hydratePosts(ctx context.Context, reqUserID, userID xid.ID, posts []entity.Post) ([]entity.FullPost, error) {
+	if len(posts) == 0 {
+		return []entity.FullPost{}, nil
+	}
+
+	ids := make([]xid.ID, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].PostID
+	}
+
+	contents, err := ts.postService.BatchGetPosts(ctx, reqUserID, ids)
+	if err != nil {
+		return nil, ucerr.NewInternalError(err)
+	}
+
+	contentByID := make(map[xid.ID]entity.PostContent, len(contents))
+	for i := range contents {
+		contentByID[contents[i].ID] = contents[i]
+	}
+
+	res := make([]entity.FullPost, 0, len(posts))
+	deleted := make([]entity.Post, 0)
+	for i := range posts {
+		c, ok := contentByID[posts[i].PostID]
+		if !ok {
+			// not found: it may be not replicated yet, so omit but keep in timeline
+			continue
+		}
+		if c.Deleted {
+			deleted = append(deleted, posts[i])
+			continue
+		}
+		res = append(res, entity.FullPost{
+			Post:        posts[i],
+			Text:        c.Text,
+			UpdatedTime: c.UpdatedTime,
+		})
+	}
+
+	if len(deleted) > 0 {
+		go ts.removePosts(userID, deleted, true)
+	}
+
+	return res, nil
+}
+
+// removePosts lazily removes posts from the user timeline
+// and, if withAuthorLists, from the recent posts lists of their authors.
+func (ts TimelineService) removePosts(userID xid.ID, posts []entity.Post, withAuthorLists bool) {
+	if len(posts) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ts.shutdownCtx, removePostsTimeout)
+	defer cancel()
+
+	for i := range posts {
+		if err := ts.repo.ExistedListDeletePost(ctx, userID, posts[i]); err != nil {
+			ts.logger.Warn().
+				Err(err).
+				Str("user_id", userID.String()).
+				Str("post_id", posts[i].PostID.String()).
+				Msg("failed to remove post from timeline")
+			return
+		}
+		if !withAuthorLists {
+			continue
+		}
+		if err := ts.repo.ExistedUserListDeletePost(ctx, posts[i].AuthorID, posts[i]); err != nil {
+			ts.logger.Warn().
+				Err(err).
+				Str("author_id", posts[i].AuthorID.String()).
+				Str("post_id", posts[i].PostID.String()).
+				Msg("failed to remove post from recent posts")
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// contentPostService returns the given contents of the posts.
+type contentPostService struct {
+	fakePostService
+	contents []entity.PostContent
+}
+
+func (s contentPostService) BatchGetPosts(_ context.Context, _ xid.ID, postIDs []xid.ID) ([]entity.PostContent, error) {
+	res := make([]entity.PostContent, 0, len(postIDs))
+	for i := range s.contents {
+		if slices.Contains(postIDs, s.contents[i].ID) {
+			res = append(res, s.contents[i])
+		}
+	}
+	return res, nil
+}
+
+func TestTimelineService_GetFullTimeline(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID := xid.New(), xid.New()
+	posts := make([]entity.Post, 3)
+	for i := range posts {
+		posts[len(posts)-1-i] = entity.Post{PostID: xid.New(), AuthorID: authorID}
+	}
+	updated := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {authorID},
+	}}
+	ts := newTestService(t, relations, contentPostService{
+		fakePostService: fakePostService{posts: posts},
+		contents: []entity.PostContent{
+			{ID: posts[0].PostID, AuthorID: authorID, Text: "first", UpdatedTime: updated},
+			{ID: posts[1].PostID, AuthorID: authorID, Deleted: true},
+			// the content of the last post is not replicated yet
+		},
+	})
+
+	res, _, err := ts.GetFullTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.FullPost{{Post: posts[0], Text: "first", UpdatedTime: updated}}, res)
+
+	// the deleted post is removed from the timeline and the recent posts of the author,
+	// the post without content is kept
+	assert.Eventually(t, func() bool {
+		authorPosts, err := ts.repo.UserListGet(ctx, []xid.ID{authorID}, 10)
+		require.NoError(t, err)
+		return len(authorPosts[authorID]) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	timeline, err := ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{posts[0], posts[2]}, timeline)
+
+	authorPosts, err := ts.repo.UserListGet(ctx, []xid.ID{authorID}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{posts[0], posts[2]}, authorPosts[authorID])
+}
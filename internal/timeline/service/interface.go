@@ -6,19 +6,83 @@ import (
 
 	"github.com/rs/xid"
 
+	tlbroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
 	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
 )
 
 type repo interface {
-	// ListGet returns timeline list from cache.
-	ListGet(ctx context.Context, userID xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error)
-	// ExistedListPush push timeline record to existed timeline list or do nothing if timeline list does not exist.
-	ExistedListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) error
-	// ListSet set timeline list to cache (new records must be at the end).
-	ListSet(ctx context.Context, userID xid.ID, posts []entity.Post, ttl time.Duration) error
+	// ListGet returns timeline list from cache: records older than cursor record (from the head if cursor is nil)
+	// skipping offset records.
+	ListGet(ctx context.Context, userID, cursor xid.ID, offset, limit int, ttl *time.Duration) ([]entity.Post, error)
+	// ListGetNewer returns the count of timeline records newer than sinceID record
+	// and up to limit newest of them from cache.
+	ListGetNewer(ctx context.Context, userID, sinceID xid.ID, limit int) ([]entity.Post, int, error)
+	// ExistedListPushPost inserts timeline record to existed timeline list at its chronological position
+	// and reports whether it was inserted: it does nothing if timeline list does not exist
+	// or already contains the post (e.g. reposted by other user).
+	ExistedListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) (bool, error)
+	// ListMeta returns the metadata of timeline list or ErrNotFound if timeline does not exist.
+	ListMeta(ctx context.Context, userID xid.ID) (entity.TimelineMeta, error)
+	// ListSet set timeline list to cache (records must be ordered from newest to oldest)
+	// together with its build metadata: the schema version and the count of meta are set by the repo.
+	ListSet(ctx context.Context, userID xid.ID, posts []entity.Post, meta entity.TimelineMeta, ttl time.Duration) error
+	// ListUpdate atomically replaces timeline records with the result of fn applied to them
+	// (ordered from newest to oldest) or returns ErrNotFound if timeline does not exist.
+	// The fn may be called several times if timeline is changed concurrently.
+	ListUpdate(ctx context.Context, userID xid.ID, fn func([]entity.Post) []entity.Post, ttl time.Duration) error
+	// ExistedListsPushPosts push records to existed timeline lists of several users at once
+	// (records of each user are pushed in the given order) and reports whether each of them was pushed.
+	ExistedListsPushPosts(ctx context.Context, posts map[xid.ID][]entity.Post, limit int64) (map[xid.ID][]bool, error)
+	// ExistedListDeletePost removes the post and its reposts from timeline list.
 	ExistedListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error
 	// ExistedListDelete romoves timeline list by userID or do nothing if timeline list does not exist.
 	ExistedListDelete(ctx context.Context, userID xid.ID) error
+	// ColdListGet returns the cold copy of timeline list kept after the timeline expires
+	// together with its build metadata or ErrNotFound if there is no copy.
+	ColdListGet(ctx context.Context, userID xid.ID) ([]entity.Post, entity.TimelineMeta, error)
+	// ColdListDelete removes the cold copy of timeline list, so the timeline is built from scratch.
+	ColdListDelete(ctx context.Context, userID xid.ID) error
+	// UserListGet returns recent posts lists of the authors from cache,
+	// authors without cached list are absent in the result.
+	UserListGet(ctx context.Context, userIDs []xid.ID, limit int) (map[xid.ID][]entity.Post, error)
+	// UserListSet set recent posts list of the author to cache (records must be ordered from newest to oldest).
+	UserListSet(ctx context.Context, userID xid.ID, posts []entity.Post, ttl time.Duration) error
+	// ExistedUserListPushPost inserts post to existed recent posts list of the author at its chronological position
+	// or do nothing if list does not exist or already contains the post.
+	ExistedUserListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) error
+	// ExistedUserListDeletePost removes the post from recent posts list of the author.
+	ExistedUserListDeletePost(ctx context.Context, userID xid.ID, post entity.Post) error
+	// ExistedUserListDelete removes recent posts list of the author or do nothing if list does not exist.
+	ExistedUserListDelete(ctx context.Context, userID xid.ID) error
+	// CelebrityGet returns whether user is a celebrity or ErrNotFound if status is unknown or expired.
+	CelebrityGet(ctx context.Context, userID xid.ID) (bool, error)
+	// CelebritySet saves checked celebrity status of the user.
+	CelebritySet(ctx context.Context, userID xid.ID, isCelebrity bool, ttl time.Duration) error
+	// CelebrityFilter returns only those of userIDs that are known as celebrities.
+	CelebrityFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error)
+	// FollowedCelebritiesGet returns the celebrities followed by the user
+	// or ErrNotFound if they are unknown or expired.
+	FollowedCelebritiesGet(ctx context.Context, userID xid.ID) (entity.FollowedCelebrities, error)
+	// FollowedCelebritiesSet saves the celebrities followed by the user.
+	FollowedCelebritiesSet(ctx context.Context, userID xid.ID, celebrities entity.FollowedCelebrities, ttl time.Duration) error
+	// TombstoneAdd marks the post as deleted for all timelines for ttl
+	// or does nothing if the post is already marked.
+	TombstoneAdd(ctx context.Context, postID xid.ID, ttl time.Duration) error
+	// TombstoneFilter returns only those of postIDs that are marked as deleted.
+	TombstoneFilter(ctx context.Context, postIDs []xid.ID) ([]xid.ID, error)
+	// UserTombstoneAdd marks the user as deleted for all timelines for ttl
+	// or does nothing if the user is already marked.
+	UserTombstoneAdd(ctx context.Context, userID xid.ID, ttl time.Duration) error
+	// UserTombstoneFilter returns only those of userIDs that are marked as deleted.
+	UserTombstoneFilter(ctx context.Context, userIDs []xid.ID) ([]xid.ID, error)
+}
+
+type broker interface {
+	// Publish publishes the event to the user timeline watchers,
+	// it does nothing if the user has no watchers.
+	Publish(ctx context.Context, userID xid.ID, event entity.TimelineEvent) error
+	// Subscribe subscribes to the user timeline events.
+	Subscribe(ctx context.Context, userID xid.ID) (*tlbroker.Subscription, error)
 }
 
 type relationService interface {
@@ -27,5 +91,9 @@ type relationService interface {
 }
 
 type postService interface {
+	// ListPostIDsByUserIDs returns recent posts of the users visible to the requesting user,
+	// nil reqUserID requests them on behalf of the service itself (unfiltered).
 	ListPostIDsByUserIDs(ctx context.Context, reqUserID xid.ID, userIDs []xid.ID, limit int) ([]entity.Post, error)
+	// BatchGetPosts returns content of the posts, not found posts are omitted.
+	BatchGetPosts(ctx context.Context, reqUserID xid.ID, postIDs []xid.ID) ([]entity.PostContent, error)
 }
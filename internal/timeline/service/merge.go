@@ -0,0 +1,115 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// mergePosts merges two lists of posts ordered by sort id (newest first)
+// into one ordered list of at most limit posts without duplicates of the same post.
+func mergePosts(a, b []entity.Post, limit int) []entity.Post {
+	res := make([]entity.Post, 0, min(limit, len(a)+len(b)))
+	seen := make(map[xid.ID]struct{}, cap(res))
+
+	var i, j int
+	for len(res) < limit && (i < len(a) || j < len(b)) {
+		var p entity.Post
+		switch {
+		case i == len(a):
+			p = b[j]
+			j++
+		case j == len(b):
+			p = a[i]
+			i++
+		case a[i].SortID().Compare(b[j].SortID()) > 0:
+			p = a[i]
+			i++
+		default:
+			p = b[j]
+			j++
+		}
+
+		if _, ok := seen[p.PostID]; ok {
+			continue
+		}
+		seen[p.PostID] = struct{}{}
+		res = append(res, p)
+	}
+
+	return res
+}
+
+// withoutUserPosts removes posts authored or reposted by the user.
+func withoutUserPosts(posts []entity.Post, userID xid.ID) []entity.Post {
+	return slices.DeleteFunc(posts, func(p entity.Post) bool {
+		return isUserPost(p, userID)
+	})
+}
+
+// isUserPost reports whether the post is authored or reposted by the user.
+func isUserPost(p entity.Post, userID xid.ID) bool {
+	return p.AuthorID.Compare(userID) == 0 ||
+		(p.IsRepost && p.RepostedBy.Compare(userID) == 0)
+}
+
+// withoutRecords removes the timeline records matched by remove, but turns the matched reposts
+// back into the original posts if their authors are among followingIDs: the timeline holds
+// one record per post, so the repost may be the only record of the followed author post.
+func withoutRecords(posts []entity.Post, followingIDs []xid.ID, limit int, remove func(entity.Post) bool) []entity.Post {
+	var restored []entity.Post
+	posts = slices.DeleteFunc(posts, func(p entity.Post) bool {
+		if !remove(p) {
+			return false
+		}
+		if p.IsRepost && slices.Contains(followingIDs, p.AuthorID) {
+			restored = append(restored, entity.Post{AuthorID: p.AuthorID, PostID: p.PostID})
+		}
+		return true
+	})
+	if len(restored) == 0 {
+		return posts
+	}
+
+	// the restored posts are ordered by the post id instead of the repost id
+	slices.SortFunc(restored, func(a, b entity.Post) int {
+		return b.PostID.Compare(a.PostID)
+	})
+	return mergePosts(posts, restored, limit)
+}
+
+// window returns the part of posts specified by offset and limit.
+func window(posts []entity.Post, offset, limit int) []entity.Post {
+	if offset >= len(posts) {
+		return []entity.Post{}
+	}
+	return posts[offset:min(offset+limit, len(posts))]
+}
+
+// olderThan returns posts ordered after the cursor sort id (all posts if cursor is nil).
+func olderThan(posts []entity.Post, cursor xid.ID) []entity.Post {
+	if cursor.IsNil() {
+		return posts
+	}
+
+	i := slices.IndexFunc(posts, func(p entity.Post) bool {
+		return p.SortID().Compare(cursor) < 0
+	})
+	if i == -1 {
+		return []entity.Post{}
+	}
+	return posts[i:]
+}
+
+// newerThan returns posts ordered before the since sort id.
+func newerThan(posts []entity.Post, since xid.ID) []entity.Post {
+	i := slices.IndexFunc(posts, func(p entity.Post) bool {
+		return p.SortID().Compare(since) <= 0
+	})
+	if i == -1 {
+		return posts
+	}
+	return posts[:i]
+}
@@ -13,67 +13,127 @@ import (
 )
 
 func (ts TimelineService) PushTimelinePost(ctx context.Context, userID xid.ID, post entity.Post) error {
-	if err := ts.repo.ExistedListPushPost(ctx, userID, post, int64(ts.cfg.Limit)); err != nil {
+	isCelebrity, err := ts.fanOutPost(ctx, post)
+	if err != nil {
 		return ucerr.NewInternalError(err)
 	}
+	if isCelebrity {
+		// celebrity posts are merged into timeline at read time
+		ts.publish(ctx, userID, entity.EventTypePostInserted, post)
+		return nil
+	}
+
+	return ts.pushTimelinePost(ctx, userID, post)
+}
+
+// PushTimelineRepost pushes the repost to the user timeline: the original post
+// is shown only once, even if several followed users reposted it.
+// The repost is placed by its repost id, it is never placed before the original post.
+// If the records are written in the format without the repost id (entity.PostEncodingV1),
+// the repost is placed by the original post id, so a repost of an old post may be
+// trimmed from the full timeline at once.
+func (ts TimelineService) PushTimelineRepost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	post.IsRepost = true
+	switch {
+	case !entity.PostEncodingKeepsRepostID():
+		// the record would be read back without it anyway
+		post.RepostID = xid.NilID()
+	case post.RepostID.Compare(post.PostID) < 0:
+		post.RepostID = post.PostID
+	}
+
+	return ts.pushTimelinePost(ctx, userID, post)
+}
+
+func (ts TimelineService) pushTimelinePost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	pushed, err := ts.repo.ExistedListPushPost(ctx, userID, post, int64(ts.cfg.Limit))
+	if err != nil {
+		return ucerr.NewInternalError(err)
+	}
+
+	if pushed {
+		ts.publish(ctx, userID, entity.EventTypePostInserted, post)
+	}
 
 	return nil
 }
 
 func (ts TimelineService) DeleteTimelinePost(ctx context.Context, userID xid.ID, post entity.Post) error {
+	// the tombstone hides the post in timelines whose delete tasks were lost
+	if err := ts.repo.TombstoneAdd(ctx, post.PostID, ts.cfg.TombstoneTTL); err != nil {
+		return ucerr.NewInternalError(err)
+	}
+	if err := ts.repo.ExistedUserListDeletePost(ctx, post.AuthorID, post); err != nil {
+		return ucerr.NewInternalError(err)
+	}
 	if err := ts.repo.ExistedListDeletePost(ctx, userID, post); err != nil {
 		return ucerr.NewInternalError(err)
 	}
 
+	ts.publish(ctx, userID, entity.EventTypePostDeleted, post)
+
 	return nil
 }
 
+// DeleteTimeline removes timelines of the deleted user and hides posts authored
+// or reposted by them in all timelines.
 func (ts TimelineService) DeleteTimeline(ctx context.Context, userID xid.ID) error {
+	// the tombstone hides posts of the user in followers timelines,
+	// they are removed from the cached lists lazily at read time
+	if err := ts.repo.UserTombstoneAdd(ctx, userID, ts.cfg.TombstoneTTL); err != nil {
+		return ucerr.NewInternalError(err)
+	}
 	if err := ts.repo.ExistedListDelete(ctx, userID); err != nil {
 		return ucerr.NewInternalError(err)
 	}
+	if err := ts.repo.ExistedUserListDelete(ctx, userID); err != nil {
+		return ucerr.NewInternalError(err)
+	}
 
 	return nil
 }
 
 func (ts TimelineService) SubscribeOnUser(ctx context.Context, userID, targetUserID xid.ID) error {
-	posts, err := ts.repo.ListGet(ctx, userID, 0, ts.cfg.Limit, nil)
-	if err != nil {
+	// the timeline is read to be updated, so replication lag is not acceptable
+	ctx = repoerr.WithPrimaryRead(ctx)
+
+	if err := ts.expireFollowedCelebrities(ctx, userID); err != nil {
+		return ucerr.NewInternalError(err)
+	}
+
+	// don't request posts of the target user if timeline does not exist
+	if _, err := ts.repo.ListMeta(ctx, userID); err != nil {
 		if errors.Is(err, repoerr.ErrNotFound) {
+			// the caught up timeline would miss older posts of the target user
+			if err := ts.repo.ColdListDelete(ctx, userID); err != nil {
+				return ucerr.NewInternalError(err)
+			}
 			return nil
 		}
 		return ucerr.NewInternalError(err)
 	}
 
-	targetPosts, err := ts.postService.ListPostIDsByUserIDs(ctx, targetUserID, []xid.ID{targetUserID}, ts.cfg.Limit)
+	isCelebrity, err := ts.isCelebrity(ctx, targetUserID)
 	if err != nil {
 		return ucerr.NewInternalError(err)
 	}
+	if isCelebrity {
+		// celebrity posts are merged into timeline at read time
+		return nil
+	}
 
-	// merge results by time
-	res := make([]entity.Post, min(ts.cfg.Limit, len(posts)+len(targetPosts)))
-	var i, j int
-	for k := 0; k < len(res); k++ {
-		if i == len(posts) {
-			res[k] = targetPosts[j]
-			j++
-			continue
-		}
-		if j == len(targetPosts) {
-			res[k] = posts[i]
-			i++
-			continue
-		}
-		if posts[i].PostID.Time().After(targetPosts[j].PostID.Time()) {
-			res[k] = posts[i]
-			i++
-		} else {
-			res[k] = targetPosts[j]
-			j++
-		}
+	targetPosts, err := ts.userPosts(ctx, targetUserID)
+	if err != nil {
+		return ucerr.NewInternalError(err)
 	}
 
-	if err := ts.repo.ListSet(ctx, userID, res, ts.cfg.TTL); err != nil {
+	// posts pushed concurrently are not lost
+	if err := ts.repo.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		return mergePosts(posts, targetPosts, ts.cfg.Limit)
+	}, ts.cfg.TTL); err != nil {
+		if errors.Is(err, repoerr.ErrNotFound) {
+			return nil
+		}
 		return ucerr.NewInternalError(err)
 	}
 
@@ -81,24 +141,71 @@ func (ts TimelineService) SubscribeOnUser(ctx context.Context, userID, targetUse
 }
 
 func (ts TimelineService) UnsubscribeFromUser(ctx context.Context, userID, targetUserID xid.ID) error {
-	posts, err := ts.repo.ListGet(ctx, userID, 0, ts.cfg.Limit, nil)
+	// the timeline is read to be updated, so replication lag is not acceptable
+	ctx = repoerr.WithPrimaryRead(ctx)
+
+	if err := ts.expireFollowedCelebrities(ctx, userID); err != nil {
+		return ucerr.NewInternalError(err)
+	}
+
+	// reposts by the target user of the posts of other followings are turned back into the original posts
+	followingIDs, err := ts.relationService.ListNotMutedFollowingIDs(ctx, userID)
 	if err != nil {
+		return ucerr.NewInternalError(err)
+	}
+	followingIDs = slices.DeleteFunc(followingIDs, func(id xid.ID) bool {
+		return id.Compare(targetUserID) == 0
+	})
+	withoutTargetPosts := func(posts []entity.Post) []entity.Post {
+		return withoutRecords(posts, followingIDs, ts.cfg.Limit, func(p entity.Post) bool {
+			return isUserPost(p, targetUserID)
+		})
+	}
+
+	var size int
+	if err := ts.repo.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		res := withoutTargetPosts(posts)
+		size = len(res)
+		return res
+	}, ts.cfg.TTL); err != nil {
 		if errors.Is(err, repoerr.ErrNotFound) {
 			return nil
 		}
 		return ucerr.NewInternalError(err)
 	}
 
-	res := slices.DeleteFunc(posts, func(p entity.Post) bool {
-		return p.AuthorID.Compare(targetUserID) == 0
-	})
+	if size >= ts.cfg.MinSize {
+		return nil
+	}
 
-	// TODO: check if user has too low number of posts
-	// and rebuild timeline if needed
+	backfill, err := ts.backfillPosts(ctx, followingIDs, targetUserID)
+	if err != nil {
+		// keep the timeline without backfill
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to backfill timeline")
+		return nil
+	}
 
-	if err := ts.repo.ListSet(ctx, userID, res, ts.cfg.TTL); err != nil {
+	if err := ts.repo.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		return mergePosts(withoutTargetPosts(posts), backfill, ts.cfg.Limit)
+	}, ts.cfg.TTL); err != nil {
+		if errors.Is(err, repoerr.ErrNotFound) {
+			return nil
+		}
 		return ucerr.NewInternalError(err)
 	}
 
 	return nil
 }
+
+// MuteUser removes posts of the muted target user from the user timeline.
+func (ts TimelineService) MuteUser(ctx context.Context, userID, targetUserID xid.ID) error {
+	return ts.UnsubscribeFromUser(ctx, userID, targetUserID)
+}
+
+// UnmuteUser merges posts of the unmuted target user back into the user timeline.
+func (ts TimelineService) UnmuteUser(ctx context.Context, userID, targetUserID xid.ID) error {
+	return ts.SubscribeOnUser(ctx, userID, targetUserID)
+}
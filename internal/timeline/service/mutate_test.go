@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	tlbroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	memoryBroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker/memory"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	memoryRepo "github.com/Karzoug/meower-timeline-service/internal/timeline/repo/memory"
+)
+
+type fakeRelationService struct {
+	followings map[xid.ID][]xid.ID
+}
+
+func (s fakeRelationService) ListFollowerIDs(_ context.Context, userID xid.ID) ([]xid.ID, error) {
+	var res []xid.ID
+	for followerID, followings := range s.followings {
+		if slices.Contains(followings, userID) {
+			res = append(res, followerID)
+		}
+	}
+	return res, nil
+}
+
+func (s fakeRelationService) ListNotMutedFollowingIDs(_ context.Context, userID xid.ID) ([]xid.ID, error) {
+	return slices.Clone(s.followings[userID]), nil
+}
+
+type fakePostService struct {
+	posts []entity.Post // ordered from newest to oldest
+}
+
+func (s fakePostService) ListPostIDsByUserIDs(_ context.Context, _ xid.ID, userIDs []xid.ID, limit int) ([]entity.Post, error) {
+	res := make([]entity.Post, 0)
+	for i := range s.posts {
+		if len(res) < limit && slices.Contains(userIDs, s.posts[i].AuthorID) {
+			res = append(res, s.posts[i])
+		}
+	}
+	return res, nil
+}
+
+func (s fakePostService) BatchGetPosts(_ context.Context, _ xid.ID, postIDs []xid.ID) ([]entity.PostContent, error) {
+	res := make([]entity.PostContent, 0, len(postIDs))
+	for i := range s.posts {
+		if slices.Contains(postIDs, s.posts[i].PostID) {
+			res = append(res, entity.PostContent{
+				ID:       s.posts[i].PostID,
+				AuthorID: s.posts[i].AuthorID,
+			})
+		}
+	}
+	return res, nil
+}
+
+func newTestService(t *testing.T, relations fakeRelationService, posts postService) TimelineService {
+	t.Helper()
+
+	closeChan := make(chan struct{})
+	t.Cleanup(func() { close(closeChan) })
+
+	ts, err := NewTimelineService(Config{
+		Limit:             100,
+		TTL:               time.Hour,
+		MinSize:           2,
+		TombstoneTTL:      time.Hour,
+		BuildTimeout:      time.Second,
+		UserTimelineLimit: 10,
+		UserTimelineTTL:   time.Hour,
+		CelebrityCheckTTL: time.Hour,
+	},
+		memoryRepo.NewTimelineRepo(),
+		relations,
+		posts,
+		memoryBroker.NewBroker(tlbroker.Config{BufferSize: 16, MaxPerUser: 1}),
+		closeChan,
+		noop.NewTracerProvider().Tracer(""),
+		zerolog.Nop(),
+	)
+	require.NoError(t, err)
+
+	return ts
+}
+
+func postIDs(posts []entity.Post) []xid.ID {
+	res := make([]xid.ID, len(posts))
+	for i := range posts {
+		res[i] = posts[i].PostID
+	}
+	return res
+}
+
+// withPostEncoding sets the format of the timeline records for the test.
+func withPostEncoding(t *testing.T, e entity.PostEncoding) {
+	t.Helper()
+
+	require.NoError(t, entity.SetPostEncoding(e))
+	t.Cleanup(func() { _ = entity.SetPostEncoding(entity.PostEncodingV1) })
+}
+
+func TestTimelineService_SubscribeUnsubscribe(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, author1, author2, author3 := xid.New(), xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: author1}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: author2}
+	post3 := entity.Post{PostID: xid.New(), AuthorID: author3}
+	post4 := entity.Post{PostID: xid.New(), AuthorID: author1}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {author1, author3},
+	}}
+	ts := newTestService(t, relations, fakePostService{
+		posts: []entity.Post{post4, post3, post2, post1},
+	})
+
+	// timeline is built from scratch on the first read
+	posts, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post4.PostID, post3.PostID, post1.PostID}, postIDs(posts))
+
+	// posts of the new following are merged at their chronological position
+	relations.followings[userID] = append(relations.followings[userID], author2)
+	require.NoError(t, ts.SubscribeOnUser(ctx, userID, author2))
+
+	posts, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post4.PostID, post3.PostID, post2.PostID, post1.PostID}, postIDs(posts))
+
+	// thin timeline is backfilled after removing posts of the user
+	relations.followings[userID] = []xid.ID{author2, author3}
+	require.NoError(t, ts.UnsubscribeFromUser(ctx, userID, author1))
+
+	posts, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post3.PostID, post2.PostID}, postIDs(posts))
+}
+
+func TestTimelineService_PushTimelinePost(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID := xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+
+	ts := newTestService(t,
+		fakeRelationService{followings: map[xid.ID][]xid.ID{userID: {authorID}}},
+		fakePostService{posts: []entity.Post{post1}})
+
+	// post is not pushed to not existed timeline
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, post2))
+
+	posts, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post1.PostID}, postIDs(posts))
+
+	sub, err := ts.broker.Subscribe(ctx, userID)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, ts.PushTimelinePost(ctx, userID, post2))
+
+	posts, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post2.PostID, post1.PostID}, postIDs(posts))
+
+	select {
+	case event := <-sub.Events():
+		assert.Equal(t, entity.EventTypePostInserted, event.Type)
+		assert.Equal(t, post2.PostID, event.Post.PostID)
+	default:
+		t.Fatal("event is not published")
+	}
+}
+
+func TestTimelineService_PushTimelineRepost(t *testing.T) {
+	ctx := context.TODO()
+	withPostEncoding(t, entity.PostEncodingV2)
+
+	userID, authorID, reposterID := xid.New(), xid.New(), xid.New()
+	oldPost := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-2 * time.Hour)), AuthorID: xid.New()}
+	post1 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Minute)), AuthorID: reposterID}
+
+	ts := newTestService(t,
+		fakeRelationService{followings: map[xid.ID][]xid.ID{userID: {authorID, reposterID}}},
+		fakePostService{posts: []entity.Post{post2, post1}})
+
+	_, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+
+	// the old post is placed by the repost time
+	repost := oldPost
+	repost.RepostedBy = reposterID
+	repost.RepostID = xid.New()
+	require.NoError(t, ts.PushTimelineRepost(ctx, userID, repost))
+
+	var (
+		res   []xid.ID
+		token string
+	)
+	for range 10 {
+		posts, next, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 1, Token: token})
+		require.NoError(t, err)
+		res = append(res, postIDs(posts)...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	assert.Equal(t, []xid.ID{oldPost.PostID, post2.PostID, post1.PostID}, res)
+}
+
+func TestTimelineService_PushTimelineRepost_DefaultEncoding(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID, reposterID := xid.New(), xid.New(), xid.New()
+	oldPost := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-2 * time.Hour)), AuthorID: xid.New()}
+	post1 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Minute)), AuthorID: reposterID}
+
+	ts := newTestService(t,
+		fakeRelationService{followings: map[xid.ID][]xid.ID{userID: {authorID, reposterID}}},
+		fakePostService{posts: []entity.Post{post2, post1}})
+
+	_, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+
+	// the version 1 records can't keep the repost id,
+	// so the old post is placed by the original post time
+	repost := oldPost
+	repost.RepostedBy = reposterID
+	repost.RepostID = xid.New()
+	require.NoError(t, ts.PushTimelineRepost(ctx, userID, repost))
+
+	posts, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post2.PostID, post1.PostID, oldPost.PostID}, postIDs(posts))
+	assert.True(t, posts[2].RepostID.IsNil())
+}
+
+// viewerPostService hides posts from some requesting users.
+type viewerPostService struct {
+	fakePostService
+	hidden map[xid.ID][]xid.ID // requesting user id -> hidden post ids
+}
+
+func (s viewerPostService) ListPostIDsByUserIDs(ctx context.Context, reqUserID xid.ID, userIDs []xid.ID, limit int) ([]entity.Post, error) {
+	posts, err := s.fakePostService.ListPostIDsByUserIDs(ctx, reqUserID, userIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(posts, func(p entity.Post) bool {
+		return slices.Contains(s.hidden[reqUserID], p.PostID)
+	}), nil
+}
+
+func TestTimelineService_SubscribeOnUser_SharedAuthorPosts(t *testing.T) {
+	ctx := context.TODO()
+
+	user1, user2, authorID := xid.New(), xid.New(), xid.New()
+	post := entity.Post{PostID: xid.New(), AuthorID: authorID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{}}
+	ts := newTestService(t, relations, viewerPostService{
+		fakePostService: fakePostService{posts: []entity.Post{post}},
+		hidden:          map[xid.ID][]xid.ID{user1: {post.PostID}},
+	})
+
+	for _, userID := range []xid.ID{user1, user2} {
+		_, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+		require.NoError(t, err)
+	}
+
+	// the cached author posts filled on subscribe of the first user are not filtered for them
+	for _, userID := range []xid.ID{user1, user2} {
+		relations.followings[userID] = []xid.ID{authorID}
+		require.NoError(t, ts.SubscribeOnUser(ctx, userID, authorID))
+	}
+
+	posts, _, err := ts.GetTimeline(ctx, user2, user2, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post.PostID}, postIDs(posts))
+}
+
+func TestTimelineService_UnsubscribeFromUser_Repost(t *testing.T) {
+	ctx := context.TODO()
+	withPostEncoding(t, entity.PostEncodingV2)
+
+	userID, authorID, reposterID := xid.New(), xid.New(), xid.New()
+	post := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: authorID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {reposterID},
+	}}
+	ts := newTestService(t, relations, fakePostService{posts: []entity.Post{post}})
+	ts.cfg.MinSize = 0 // no backfill
+
+	_, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+
+	repost := post
+	repost.RepostedBy = reposterID
+	repost.RepostID = xid.New()
+	require.NoError(t, ts.PushTimelineRepost(ctx, userID, repost))
+
+	// the repost sorts higher, so it stays the only record of the post
+	relations.followings[userID] = append(relations.followings[userID], authorID)
+	require.NoError(t, ts.SubscribeOnUser(ctx, userID, authorID))
+
+	res, err := ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.True(t, res[0].IsRepost)
+
+	// the post of the still followed author is kept
+	require.NoError(t, ts.UnsubscribeFromUser(ctx, userID, reposterID))
+
+	res, err = ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post}, res)
+}
+
+func TestTimelineService_UnsubscribeFromUser_Backfill(t *testing.T) {
+	ctx := context.TODO()
+	withPostEncoding(t, entity.PostEncodingV2)
+
+	userID, author1, author2 := xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-4 * time.Hour)), AuthorID: author2}
+	post2 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-3 * time.Hour)), AuthorID: author2}
+	post3 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-2 * time.Hour)), AuthorID: author1}
+	post4 := entity.Post{PostID: xid.NewWithTime(time.Now().Add(-time.Hour)), AuthorID: author1}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {author1, author2},
+	}}
+	ts := newTestService(t, relations, fakePostService{posts: []entity.Post{post4, post3, post2, post1}})
+	ts.cfg.Limit = 3
+
+	// the oldest post doesn't fit the timeline
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []xid.ID{post4.PostID, post3.PostID, post2.PostID}, postIDs(res))
+
+	// author2 reposted the post of author1
+	repost := post4
+	repost.IsRepost = true
+	repost.RepostedBy = author2
+	repost.RepostID = xid.New()
+	require.NoError(t, ts.repo.UserListSet(ctx, author2, []entity.Post{repost, post2, post1}, time.Hour))
+
+	// the thin timeline is backfilled from the rest followings without posts of the removed author
+	relations.followings[userID] = []xid.ID{author2}
+	require.NoError(t, ts.UnsubscribeFromUser(ctx, userID, author1))
+
+	res, err = ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post2, post1}, res)
+}
+
+// countingRepo counts the author level calls of the post fan-out.
+type countingRepo struct {
+	repo
+	userListPushes atomic.Int32
+	celebrityGets  atomic.Int32
+}
+
+func (r *countingRepo) ExistedUserListPushPost(ctx context.Context, userID xid.ID, post entity.Post, limit int64) error {
+	r.userListPushes.Add(1)
+	return r.repo.ExistedUserListPushPost(ctx, userID, post, limit)
+}
+
+func (r *countingRepo) CelebrityGet(ctx context.Context, userID xid.ID) (bool, error) {
+	r.celebrityGets.Add(1)
+	return r.repo.CelebrityGet(ctx, userID)
+}
+
+func TestTimelineService_PushTimelinePost_FanOut(t *testing.T) {
+	ctx := context.TODO()
+
+	authorID := xid.New()
+	followerIDs := []xid.ID{xid.New(), xid.New(), xid.New()}
+	post1 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: authorID}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{}}
+	for _, followerID := range followerIDs {
+		relations.followings[followerID] = []xid.ID{authorID}
+	}
+	ts := newTestService(t, relations, fakePostService{posts: []entity.Post{post1}})
+	ts.cfg.CelebrityThreshold = 10
+	repo := &countingRepo{repo: ts.repo}
+	ts.repo = repo
+
+	for _, followerID := range followerIDs {
+		_, _, err := ts.GetTimeline(ctx, followerID, followerID, PaginationOptions{Limit: 10})
+		require.NoError(t, err)
+	}
+
+	// the author level work is done once for all tasks of the post
+	for _, followerID := range followerIDs {
+		require.NoError(t, ts.PushTimelinePost(ctx, followerID, post2))
+	}
+	assert.Equal(t, int32(1), repo.userListPushes.Load())
+	assert.Equal(t, int32(1), repo.celebrityGets.Load())
+
+	for _, followerID := range followerIDs {
+		posts, _, err := ts.GetTimeline(ctx, followerID, followerID, PaginationOptions{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []xid.ID{post2.PostID, post1.PostID}, postIDs(posts))
+	}
+}
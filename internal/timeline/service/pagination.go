@@ -1,6 +1,43 @@
 package service
 
+import (
+	"encoding/base64"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
 type PaginationOptions struct {
-	Limit  int
+	Limit int
+	// Token is an opaque cursor returned with the previous page.
+	Token string
+	// Offset is a deprecated fallback used only if Token is empty:
+	// the timeline changes under fan-out, so posts may be duplicated or skipped.
 	Offset int
 }
+
+// encodePageToken returns page token anchored on the sort id of the last returned post.
+func encodePageToken(sortID xid.ID) string {
+	return base64.RawURLEncoding.EncodeToString(sortID.Bytes())
+}
+
+// decodePageToken returns the sort id the page token is anchored on.
+func decodePageToken(token string) (xid.ID, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return xid.NilID(), err
+	}
+	return xid.FromBytes(b)
+}
+
+// nextPageToken returns the token of the page following the page read from the timeline
+// (empty if there are no subsequent pages). The page is taken before the deleted posts
+// are filtered out of it: they still advance the cursor, so the returned page may be shorter
+// than the limit, even empty, while the token is not.
+func nextPageToken(page []entity.Post, limit int) string {
+	if len(page) < limit || len(page) == 0 {
+		return ""
+	}
+	return encodePageToken(page[len(page)-1].SortID())
+}
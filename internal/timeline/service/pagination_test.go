@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestPageToken_RoundTrip(t *testing.T) {
+	sortID := xid.New()
+
+	got, err := decodePageToken(encodePageToken(sortID))
+	require.NoError(t, err)
+	assert.Equal(t, sortID, got)
+}
+
+func TestDecodePageToken_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "!not a token!"},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString(xid.New().Bytes()[:10])},
+		{name: "short id", token: base64.RawURLEncoding.EncodeToString(xid.New().Bytes()[:11])},
+		{name: "long id", token: base64.RawURLEncoding.EncodeToString(append(xid.New().Bytes(), 0))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodePageToken(tt.token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNextPageToken(t *testing.T) {
+	post := entity.Post{PostID: xid.New(), AuthorID: xid.New()}
+	repost := entity.Post{PostID: xid.New(), AuthorID: xid.New(), IsRepost: true, RepostedBy: xid.New(), RepostID: xid.New()}
+
+	tests := []struct {
+		name  string
+		page  []entity.Post
+		limit int
+		want  string
+	}{
+		{name: "full page", page: []entity.Post{repost, post}, limit: 2, want: encodePageToken(post.PostID)},
+		{name: "anchored on repost id", page: []entity.Post{post, repost}, limit: 2, want: encodePageToken(repost.RepostID)},
+		{name: "short page", page: []entity.Post{post}, limit: 2, want: ""},
+		{name: "empty page", page: nil, limit: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextPageToken(tt.page, tt.limit))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	ocodes "go.opentelemetry.io/otel/codes"
 	"golang.org/x/sync/singleflight"
@@ -18,12 +19,16 @@ import (
 
 const preffixSpanName = "TimelineService.Service/"
 
-func (ts TimelineService) GetTimeline(ctx context.Context, reqUserID, userID xid.ID, pgn PaginationOptions) ([]entity.Post, error) {
+// GetTimeline returns the page of the user timeline and the token of the next page
+// (empty if there are no subsequent pages). Deleted posts are filtered out of the page
+// after the token is taken, so the page may be shorter than the limit, even empty,
+// while there are subsequent pages.
+func (ts TimelineService) GetTimeline(ctx context.Context, reqUserID, userID xid.ID, pgn PaginationOptions) ([]entity.Post, string, error) {
 	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"GetTimeline")
 	defer span.End()
 
 	if pgn.Offset < 0 {
-		return nil, ucerr.NewError(
+		return nil, "", ucerr.NewError(
 			nil,
 			"invalid pagination parameter: negative offset",
 			codes.InvalidArgument,
@@ -31,7 +36,7 @@ func (ts TimelineService) GetTimeline(ctx context.Context, reqUserID, userID xid
 	}
 
 	if pgn.Limit < 0 {
-		return nil, ucerr.NewError(
+		return nil, "", ucerr.NewError(
 			nil,
 			"invalid pagination parameter: negative size",
 			codes.InvalidArgument,
@@ -43,61 +48,101 @@ func (ts TimelineService) GetTimeline(ctx context.Context, reqUserID, userID xid
 		pgn.Limit = 100
 	}
 
+	cursor := xid.NilID()
+	if pgn.Token != "" {
+		var err error
+		cursor, err = decodePageToken(pgn.Token)
+		if err != nil {
+			return nil, "", ucerr.NewError(
+				err,
+				"invalid pagination parameter: page token",
+				codes.InvalidArgument,
+			)
+		}
+		// offset is a fallback only
+		pgn.Offset = 0
+	}
+
 	if reqUserID.Compare(userID) != 0 {
-		return nil, ucerr.NewError(nil, "user id mismatch", codes.PermissionDenied)
+		return nil, "", ucerr.NewError(nil, "user id mismatch", codes.PermissionDenied)
 	}
 	if pgn.Offset >= ts.cfg.Limit {
-		return nil, ucerr.NewError(nil, "end of timeline", codes.OutOfRange)
+		return nil, "", ucerr.NewError(nil, "end of timeline", codes.OutOfRange)
 	}
 	if pgn.Offset+pgn.Limit > ts.cfg.Limit {
 		pgn.Limit = ts.cfg.Limit - pgn.Offset
 	}
 
-	res, err := ts.repo.ListGet(ctx, userID, pgn.Offset, pgn.Limit, &ts.cfg.TTL)
-	if nil == err {
-		return res, nil
+	// in hybrid mode celebrity posts are merged at read time,
+	// so the whole timeline head is needed to keep pagination correct
+	celebrityIDs := ts.readCelebrities(ctx, userID)
+	offset, limit := pgn.Offset, pgn.Limit
+	if len(celebrityIDs) > 0 {
+		offset, limit = 0, pgn.Offset+pgn.Limit
 	}
+
+	// the page may lag behind the recent pushes a bit, so it is read from replicas
+	res, err := ts.repo.ListGet(repoerr.WithReplicaRead(ctx), userID, cursor, offset, limit, &ts.cfg.TTL)
 	if errors.Is(err, repoerr.ErrNotFound) {
-		// not found timeline in cache -> build it from scratch
+		// not found timeline in cache -> rebuild it
 		span.AddEvent("timeline not found in cache")
-		return ts.getTimelineFromScratch(userID)
+		res, err = ts.rebuildTimeline(userID)
+		if err != nil {
+			return nil, "", err
+		}
+		res = window(olderThan(res, cursor), offset, limit)
+	}
+	if err != nil {
+		return nil, "", readError(err)
 	}
 
-	switch {
-	case errors.Is(err, context.Canceled):
-		return nil, ucerr.NewError(err, "request canceled", codes.Canceled)
-	case errors.Is(err, context.DeadlineExceeded):
-		return nil, ucerr.NewError(err, "request timeout", codes.DeadlineExceeded)
-	default:
-		return nil, ucerr.NewInternalError(err)
+	if len(celebrityIDs) > 0 {
+		merged, err := ts.mergeCelebrityPosts(ctx, celebrityIDs, cursor, res, limit)
+		if err != nil {
+			// degrade to the cached timeline without celebrity posts
+			span.RecordError(err)
+			ts.logger.Warn().
+				Err(err).
+				Str("user_id", userID.String()).
+				Msg("failed to merge celebrity posts")
+			merged = res
+		}
+		res = window(merged, pgn.Offset, pgn.Limit)
 	}
+
+	token := nextPageToken(res, pgn.Limit)
+	res = ts.withoutDeletedPosts(ctx, userID, res)
+
+	return res, token, nil
 }
 
-func (ts TimelineService) getTimelineFromScratch(userID xid.ID) ([]entity.Post, error) {
+// rebuildTimeline builds the timeline not found in cache and saves it to cache:
+// it is caught up from its cold copy if possible, otherwise it is built from scratch.
+func (ts TimelineService) rebuildTimeline(userID xid.ID) ([]entity.Post, error) {
 	ctx, cancel := context.WithTimeout(ts.shutdownCtx, ts.cfg.BuildTimeout)
 	defer cancel()
 
-	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"BuildTimelineFromScratch")
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"RebuildTimeline")
 	defer span.End()
 
-	res := ts.buildTimelineFromScratch(ctx, userID)
+	res := ts.buildTimeline(ctx, userID)
 
 	fn := func(r singleflight.Result) ([]entity.Post, error) {
 		if r.Err != nil {
 			return nil, ucerr.NewInternalError(r.Err)
 		}
-		res, ok := r.Val.([]entity.Post)
+		res, ok := r.Val.(timelineBuild)
 		if !ok {
-			return nil, ucerr.NewInternalError(fmt.Errorf("invalid type assertion: want []entity.Post, got %T", r.Val))
+			return nil, ucerr.NewInternalError(fmt.Errorf("invalid type assertion: want timelineBuild, got %T", r.Val))
 		}
 
-		if err := ts.repo.ListSet(ctx, userID, res, ts.cfg.TTL); err != nil {
+		if err := ts.repo.ListSet(ctx, userID, res.posts, res.meta, ts.cfg.TTL); err != nil {
 			ts.logger.Error().
 				Err(err).
 				Msg("failed to set timeline")
 		}
 
-		return res, nil
+		return res.posts, nil
 	}
 
 	var (
@@ -119,19 +164,138 @@ func (ts TimelineService) getTimelineFromScratch(userID xid.ID) ([]entity.Post,
 	return posts, err
 }
 
-func (ts TimelineService) buildTimelineFromScratch(ctx context.Context, userID xid.ID) <-chan singleflight.Result {
+// timelineBuild is the result of the timeline build.
+type timelineBuild struct {
+	posts []entity.Post
+	meta  entity.TimelineMeta
+}
+
+func (ts TimelineService) buildTimeline(ctx context.Context, userID xid.ID) <-chan singleflight.Result {
 	// suppression mechanism for set of the same requests
 	return ts.syncGroup.DoChan(userID.String(), func() (any, error) {
+		// the build time precedes the posts requests,
+		// so the posts published during the build are newer than it
+		meta := entity.TimelineMeta{
+			BuiltAt: time.Now(),
+			Source:  entity.TimelineSourceScratch,
+		}
+
 		followingIDs, err := ts.relationService.ListNotMutedFollowingIDs(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
 
-		posts, err := ts.postService.ListPostIDsByUserIDs(ctx, userID, followingIDs, ts.cfg.Limit)
+		// celebrity posts are not stored in timeline, they are merged at read time
+		followingIDs, err = ts.withoutCelebrities(ctx, followingIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if posts, ok := ts.catchUpTimeline(ctx, userID, followingIDs); ok {
+			meta.Source = entity.TimelineSourceIncremental
+			return timelineBuild{posts: posts, meta: meta}, nil
+		}
+
+		posts, err := ts.usersPosts(ctx, followingIDs, ts.cfg.Limit)
 		if err != nil {
 			return nil, err
 		}
 
-		return posts, nil
+		return timelineBuild{posts: posts, meta: meta}, nil
 	})
 }
+
+// GetNewTimelinePosts returns the count of the user timeline posts newer than sinceID post
+// and up to limit newest of them (none if countOnly).
+func (ts TimelineService) GetNewTimelinePosts(ctx context.Context, reqUserID, userID, sinceID xid.ID, limit int, countOnly bool) ([]entity.Post, int, error) {
+	ctx, span := ts.tracer.Start(ctx, preffixSpanName+"GetNewTimelinePosts")
+	defer span.End()
+
+	if limit < 0 {
+		return nil, 0, ucerr.NewError(
+			nil,
+			"invalid pagination parameter: negative size",
+			codes.InvalidArgument,
+		)
+	}
+	if limit == 0 || limit > 100 {
+		limit = 100
+	}
+	if countOnly {
+		limit = 0
+	}
+
+	if reqUserID.Compare(userID) != 0 {
+		return nil, 0, ucerr.NewError(nil, "user id mismatch", codes.PermissionDenied)
+	}
+
+	res, count, err := ts.repo.ListGetNewer(ctx, userID, sinceID, limit)
+	if errors.Is(err, repoerr.ErrNotFound) {
+		// not found timeline in cache -> rebuild it
+		span.AddEvent("timeline not found in cache")
+		res, err = ts.rebuildTimeline(userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		res = newerThan(res, sinceID)
+		count = len(res)
+		res = window(res, 0, limit)
+	}
+	if err != nil {
+		return nil, 0, readError(err)
+	}
+
+	if celebrityIDs := ts.readCelebrities(ctx, userID); len(celebrityIDs) > 0 {
+		celebrityPosts, err := ts.usersPosts(ctx, celebrityIDs, ts.cfg.Limit)
+		if err != nil {
+			// degrade to the cached timeline without celebrity posts
+			span.RecordError(err)
+			ts.logger.Warn().
+				Err(err).
+				Str("user_id", userID.String()).
+				Msg("failed to merge celebrity posts")
+		} else {
+			celebrityPosts = newerThan(celebrityPosts, sinceID)
+			count += len(celebrityPosts)
+			res = mergePosts(res, celebrityPosts, limit)
+		}
+	}
+
+	n := len(res)
+	res = ts.withoutDeletedPosts(ctx, userID, res)
+	count -= n - len(res)
+
+	return res, count, nil
+}
+
+// readCelebrities returns the celebrities followed by the user whose posts are merged
+// into the read timeline, none if hybrid mode is disabled.
+// On failure it degrades to the cached timeline without celebrity posts.
+func (ts TimelineService) readCelebrities(ctx context.Context, userID xid.ID) []xid.ID {
+	if ts.cfg.CelebrityThreshold <= 0 {
+		return nil
+	}
+
+	celebrityIDs, err := ts.followedCelebrities(ctx, userID)
+	if err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to get followed celebrities")
+		return nil
+	}
+
+	return celebrityIDs
+}
+
+// readError converts repo read error to service error.
+func readError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ucerr.NewError(err, "request canceled", codes.Canceled)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ucerr.NewError(err, "request timeout", codes.DeadlineExceeded)
+	default:
+		return ucerr.NewInternalError(err)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestTimelineService_GetTimeline_PageToken(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID := xid.New(), xid.New()
+	posts := make([]entity.Post, 5)
+	for i := range posts {
+		posts[len(posts)-1-i] = entity.Post{PostID: xid.New(), AuthorID: authorID}
+	}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {authorID},
+	}}
+	ts := newTestService(t, relations, &fakePostService{posts: posts})
+
+	readAll := func() (res []xid.ID, pages [][]xid.ID) {
+		var token string
+		for range 10 {
+			page, next, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 2, Token: token})
+			require.NoError(t, err)
+			res = append(res, postIDs(page)...)
+			pages = append(pages, postIDs(page))
+			if next == "" {
+				return res, pages
+			}
+			token = next
+		}
+		require.Fail(t, "too many pages")
+		return nil, nil
+	}
+
+	res, pages := readAll()
+	assert.Equal(t, postIDs(posts), res)
+	assert.Len(t, pages, 3)
+
+	// the deleted posts are filtered out of the page, but still advance the cursor:
+	// the second page is empty, while the subsequent page is still returned
+	require.NoError(t, ts.repo.TombstoneAdd(ctx, posts[2].PostID, time.Hour))
+	require.NoError(t, ts.repo.TombstoneAdd(ctx, posts[3].PostID, time.Hour))
+	res, pages = readAll()
+	assert.Equal(t, []xid.ID{posts[0].PostID, posts[1].PostID, posts[4].PostID}, res)
+	if assert.Len(t, pages, 3) {
+		assert.Empty(t, pages[1])
+	}
+
+	// the token is preferred to the offset
+	_, next, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 1})
+	require.NoError(t, err)
+	page, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 1, Token: next, Offset: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{posts[1].PostID}, postIDs(page))
+
+	_, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 1, Token: "!not a token!"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestTimelineService_GetNewTimelinePosts(t *testing.T) {
+	ctx := context.TODO()
+	withPostEncoding(t, entity.PostEncodingV2)
+
+	userID, authorID, reposterID := xid.New(), xid.New(), xid.New()
+	posts := make([]entity.Post, 4)
+	for i := range posts {
+		posts[len(posts)-1-i] = entity.Post{PostID: xid.New(), AuthorID: authorID}
+	}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {authorID, reposterID},
+	}}
+	ts := newTestService(t, relations, &fakePostService{posts: posts})
+
+	// not cached timeline is rebuilt
+	res, count, err := ts.GetNewTimelinePosts(ctx, userID, userID, posts[2].PostID, 10, false)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{posts[0].PostID, posts[1].PostID}, postIDs(res))
+	assert.Equal(t, 2, count)
+
+	// the count is not limited by the returned posts
+	res, count, err = ts.GetNewTimelinePosts(ctx, userID, userID, posts[3].PostID, 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{posts[0].PostID}, postIDs(res))
+	assert.Equal(t, 3, count)
+
+	res, count, err = ts.GetNewTimelinePosts(ctx, userID, userID, posts[3].PostID, 10, true)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+	assert.Equal(t, 3, count)
+
+	// no seen posts
+	res, count, err = ts.GetNewTimelinePosts(ctx, userID, userID, xid.NilID(), 10, false)
+	require.NoError(t, err)
+	assert.Equal(t, postIDs(posts), postIDs(res))
+	assert.Equal(t, 4, count)
+
+	// the repost of the old post is newer by its repost id,
+	// the deleted post is not counted
+	repost := entity.Post{
+		PostID:     xid.NewWithTime(time.Now().Add(-time.Hour)),
+		AuthorID:   xid.New(),
+		IsRepost:   true,
+		RepostedBy: reposterID,
+		RepostID:   xid.New(),
+	}
+	require.NoError(t, ts.PushTimelineRepost(ctx, userID, repost))
+	require.NoError(t, ts.repo.TombstoneAdd(ctx, posts[0].PostID, time.Hour))
+
+	res, count, err = ts.GetNewTimelinePosts(ctx, userID, userID, posts[1].PostID, 10, false)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{repost}, res)
+	assert.Equal(t, 1, count)
+
+	_, _, err = ts.GetNewTimelinePosts(ctx, userID, xid.New(), posts[1].PostID, 10, false)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, _, err = ts.GetNewTimelinePosts(ctx, userID, userID, posts[1].PostID, -1, false)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -9,12 +9,14 @@ import (
 )
 
 type TimelineService struct {
-	repo repo
+	repo   repo
+	broker broker
 	relationService
 	postService
 	cfg         Config
 	shutdownCtx context.Context // for background workers
 	syncGroup   *singleflight.Group
+	fanouts     *fanouts
 	tracer      trace.Tracer
 	logger      zerolog.Logger
 }
@@ -23,6 +25,7 @@ func NewTimelineService(cfg Config,
 	repo repo,
 	relationService relationService,
 	postService postService,
+	broker broker,
 	closeChan <-chan struct{},
 	tracer trace.Tracer,
 	logger zerolog.Logger,
@@ -42,7 +45,9 @@ func NewTimelineService(cfg Config,
 		repo:            repo,
 		relationService: relationService,
 		postService:     postService,
+		broker:          broker,
 		syncGroup:       new(singleflight.Group),
+		fanouts:         newFanouts(fanoutTTL),
 		shutdownCtx:     ctx,
 		tracer:          tracer,
 		logger:          logger,
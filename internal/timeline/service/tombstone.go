@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+	repoerr "github.com/Karzoug/meower-timeline-service/internal/timeline/repo"
+)
+
+// deletedPosts are the timeline records to be removed.
+type deletedPosts struct {
+	// posts marked as deleted
+	posts []entity.Post
+	// posts authored by deleted users
+	userPosts []entity.Post
+	// reposts by deleted users of the posts of not deleted authors: the record is
+	// the only copy of the post in the timeline, so only the repost is removed from it
+	reposts []entity.Post
+}
+
+func (d deletedPosts) empty() bool {
+	return len(d.posts) == 0 && len(d.userPosts) == 0 && len(d.reposts) == 0
+}
+
+// withoutDeletedPosts filters out posts marked as deleted and posts authored or reposted
+// by deleted users, and removes them from the user timeline in background.
+// On failure it returns posts as is.
+func (ts TimelineService) withoutDeletedPosts(ctx context.Context, userID xid.ID, posts []entity.Post) []entity.Post {
+	res, deleted, err := ts.splitDeletedPosts(ctx, posts)
+	if err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to filter deleted posts")
+		return posts
+	}
+	if deleted.empty() {
+		return posts
+	}
+
+	go ts.removeDeletedPosts(userID, posts)
+
+	return res
+}
+
+// splitDeletedPosts splits posts into the kept ones and the deleted ones.
+func (ts TimelineService) splitDeletedPosts(ctx context.Context, posts []entity.Post) ([]entity.Post, deletedPosts, error) {
+	if len(posts) == 0 {
+		return posts, deletedPosts{}, nil
+	}
+
+	postIDs := make([]xid.ID, len(posts))
+	userIDs := make([]xid.ID, 0, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].PostID
+		userIDs = append(userIDs, posts[i].AuthorID)
+		if posts[i].IsRepost {
+			userIDs = append(userIDs, posts[i].RepostedBy)
+		}
+	}
+	slices.SortFunc(userIDs, xid.ID.Compare)
+	userIDs = slices.CompactFunc(userIDs, func(a, b xid.ID) bool { return a.Compare(b) == 0 })
+
+	deletedPostIDs, err := ts.repo.TombstoneFilter(ctx, postIDs)
+	if err != nil {
+		return nil, deletedPosts{}, err
+	}
+	deletedUserIDs, err := ts.repo.UserTombstoneFilter(ctx, userIDs)
+	if err != nil {
+		return nil, deletedPosts{}, err
+	}
+	if len(deletedPostIDs) == 0 && len(deletedUserIDs) == 0 {
+		return posts, deletedPosts{}, nil
+	}
+
+	var (
+		res     = make([]entity.Post, 0, len(posts))
+		deleted deletedPosts
+	)
+	for i := range posts {
+		switch {
+		case slices.Contains(deletedPostIDs, posts[i].PostID):
+			deleted.posts = append(deleted.posts, posts[i])
+		case slices.Contains(deletedUserIDs, posts[i].AuthorID):
+			deleted.userPosts = append(deleted.userPosts, posts[i])
+		case posts[i].IsRepost && slices.Contains(deletedUserIDs, posts[i].RepostedBy):
+			deleted.reposts = append(deleted.reposts, posts[i])
+		default:
+			res = append(res, posts[i])
+		}
+	}
+
+	return res, deleted, nil
+}
+
+// removeDeletedPosts removes deleted posts found in the read posts from the whole user timeline:
+// the records deeper in the timeline may be not read until their tombstones expire,
+// but the timeline lives as long as it is read, so they would be shown again then.
+func (ts TimelineService) removeDeletedPosts(userID xid.ID, read []entity.Post) {
+	ctx, cancel := context.WithTimeout(ts.shutdownCtx, removePostsTimeout)
+	defer cancel()
+
+	// the read posts may be not in the timeline list (e.g. merged celebrity posts),
+	// but they are removed from the recent posts lists of their authors too
+	posts := read
+	list, err := ts.repo.ListGet(repoerr.WithPrimaryRead(ctx), userID, xid.NilID(), 0, ts.cfg.Limit, nil)
+	switch {
+	case err == nil:
+		posts = mergePosts(list, read, len(list)+len(read))
+	case !errors.Is(err, repoerr.ErrNotFound):
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to get timeline to remove deleted posts")
+	}
+
+	_, deleted, err := ts.splitDeletedPosts(ctx, posts)
+	if err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to filter deleted posts")
+		return
+	}
+
+	ts.removePosts(userID, deleted.posts, true)
+	// recent posts lists of deleted users are removed with their timelines
+	ts.removePosts(userID, deleted.userPosts, false)
+	ts.removeReposts(userID, deleted.reposts)
+}
+
+// removeReposts turns the reposts back into the original posts in the user timeline
+// if their authors are followed, otherwise it removes only these records.
+func (ts TimelineService) removeReposts(userID xid.ID, reposts []entity.Post) {
+	if len(reposts) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ts.shutdownCtx, removePostsTimeout)
+	defer cancel()
+
+	followingIDs, err := ts.relationService.ListNotMutedFollowingIDs(ctx, userID)
+	if err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to get followings to remove reposts")
+		return
+	}
+
+	if err := ts.repo.ListUpdate(ctx, userID, func(posts []entity.Post) []entity.Post {
+		return withoutRecords(posts, followingIDs, ts.cfg.Limit, func(p entity.Post) bool {
+			return slices.ContainsFunc(reposts, func(r entity.Post) bool {
+				return r.PostID.Compare(p.PostID) == 0 && r.RepostedBy.Compare(p.RepostedBy) == 0
+			})
+		})
+	}, ts.cfg.TTL); err != nil && !errors.Is(err, repoerr.ErrNotFound) {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to remove reposts from timeline")
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestTimelineService_WithoutDeletedPosts(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, authorID := xid.New(), xid.New()
+	posts := make([]entity.Post, 4)
+	for i := range posts {
+		posts[len(posts)-1-i] = entity.Post{PostID: xid.New(), AuthorID: authorID}
+	}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {authorID},
+	}}
+	ts := newTestService(t, relations, &fakePostService{posts: posts})
+
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts), postIDs(res))
+
+	// the delete tasks of the first and the last posts were lost
+	require.NoError(t, ts.repo.TombstoneAdd(ctx, posts[0].PostID, time.Hour))
+	require.NoError(t, ts.repo.TombstoneAdd(ctx, posts[3].PostID, time.Hour))
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 1})
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	// the deleted post deeper in the timeline is removed too
+	assert.Eventually(t, func() bool {
+		res, err := ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+		require.NoError(t, err)
+		return len(res) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	res, err = ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{posts[1].PostID, posts[2].PostID}, postIDs(res))
+}
+
+func TestTimelineService_WithoutDeletedPosts_Reposter(t *testing.T) {
+	ctx := context.TODO()
+
+	userID, reposterID, author1, author2 := xid.New(), xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: author1}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: author2}
+
+	relations := fakeRelationService{followings: map[xid.ID][]xid.ID{
+		userID: {reposterID},
+	}}
+	ts := newTestService(t, relations, &fakePostService{})
+
+	_, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+
+	// the reposts are the only records of the posts in the timeline
+	for _, post := range []entity.Post{post1, post2} {
+		post.RepostedBy = reposterID
+		post.RepostID = xid.New()
+		require.NoError(t, ts.PushTimelineRepost(ctx, userID, post))
+	}
+	relations.followings[userID] = []xid.ID{author1}
+	require.NoError(t, ts.repo.UserTombstoneAdd(ctx, reposterID, time.Hour))
+
+	res, _, err := ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	// the post of the followed author is kept as the original post
+	assert.Eventually(t, func() bool {
+		res, err := ts.repo.ListGet(ctx, userID, xid.NilID(), 0, 10, nil)
+		require.NoError(t, err)
+		return len(res) == 1 && !res[0].IsRepost
+	}, time.Second, 10*time.Millisecond)
+
+	res, _, err = ts.GetTimeline(ctx, userID, userID, PaginationOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Post{post1}, res)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/rs/xid"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// userPosts returns recent posts of the author: from cache if exists,
+// otherwise from post service with saving them to cache.
+func (ts TimelineService) userPosts(ctx context.Context, authorID xid.ID) ([]entity.Post, error) {
+	cached, err := ts.repo.UserListGet(ctx, []xid.ID{authorID}, ts.cfg.UserTimelineLimit)
+	if err != nil {
+		return nil, err
+	}
+	if posts, ok := cached[authorID]; ok {
+		return posts, nil
+	}
+
+	// the cached posts are shared by all followers, so they are not filtered for any of them
+	posts, err := ts.postService.ListPostIDsByUserIDs(ctx, xid.NilID(), []xid.ID{authorID}, ts.cfg.UserTimelineLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ts.repo.UserListSet(ctx, authorID, posts, ts.cfg.UserTimelineTTL); err != nil {
+		ts.logger.Error().
+			Err(err).
+			Str("user_id", authorID.String()).
+			Msg("failed to set user timeline")
+	}
+
+	return posts, nil
+}
+
+// usersPosts returns at most limit recent posts of the authors ordered by xid time:
+// from cache if exists, otherwise from post service with saving them to cache.
+// Recent posts of each author are limited by UserTimelineLimit, so the result is cut
+// at the oldest post of the limited lists: the older posts of other authors would be
+// returned without the missing posts of the limited author between them.
+func (ts TimelineService) usersPosts(ctx context.Context, authorIDs []xid.ID, limit int) ([]entity.Post, error) {
+	if len(authorIDs) == 0 {
+		return []entity.Post{}, nil
+	}
+
+	cached, err := ts.repo.UserListGet(ctx, authorIDs, ts.cfg.UserTimelineLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		posts     []entity.Post
+		missedIDs []xid.ID
+		cutoff    = xid.NilID() // the newest of the oldest posts of the limited lists
+	)
+	addUserPosts := func(p []entity.Post) {
+		posts = append(posts, p...)
+		if len(p) >= ts.cfg.UserTimelineLimit && p[len(p)-1].PostID.Compare(cutoff) > 0 {
+			cutoff = p[len(p)-1].PostID
+		}
+	}
+	for i := range authorIDs {
+		if p, ok := cached[authorIDs[i]]; ok {
+			addUserPosts(p)
+		} else {
+			missedIDs = append(missedIDs, authorIDs[i])
+		}
+	}
+
+	switch len(missedIDs) {
+	case 0:
+	case 1:
+		p, err := ts.userPosts(ctx, missedIDs[0])
+		if err != nil {
+			return nil, err
+		}
+		addUserPosts(p)
+	default:
+		// the posts are limited for all authors together, so they are complete up to the oldest one
+		p, err := ts.postService.ListPostIDsByUserIDs(ctx, xid.NilID(), missedIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p...)
+		ts.saveUsersPosts(ctx, missedIDs, p, len(p) < limit)
+	}
+
+	slices.SortFunc(posts, func(a, b entity.Post) int {
+		return b.PostID.Compare(a.PostID)
+	})
+	if i := slices.IndexFunc(posts, func(p entity.Post) bool {
+		return p.PostID.Compare(cutoff) < 0
+	}); i != -1 {
+		posts = posts[:i]
+	}
+
+	return mergePosts(posts, nil, limit), nil
+}
+
+// saveUsersPosts saves the posts requested for several authors at once as recent posts of each author.
+// If the result is limited for all authors together (not complete), the older posts of the author
+// may be missing, so only the authors with the full list of recent posts in the result are saved.
+func (ts TimelineService) saveUsersPosts(ctx context.Context, authorIDs []xid.ID, posts []entity.Post, complete bool) {
+	byAuthor := make(map[xid.ID][]entity.Post, len(authorIDs))
+	for i := range posts {
+		byAuthor[posts[i].AuthorID] = append(byAuthor[posts[i].AuthorID], posts[i])
+	}
+
+	for _, authorID := range authorIDs {
+		authorPosts := byAuthor[authorID]
+		if !complete && len(authorPosts) < ts.cfg.UserTimelineLimit {
+			continue
+		}
+
+		slices.SortFunc(authorPosts, func(a, b entity.Post) int {
+			return b.PostID.Compare(a.PostID)
+		})
+		authorPosts = authorPosts[:min(len(authorPosts), ts.cfg.UserTimelineLimit)]
+
+		if err := ts.repo.UserListSet(ctx, authorID, authorPosts, ts.cfg.UserTimelineTTL); err != nil {
+			ts.logger.Error().
+				Err(err).
+				Str("user_id", authorID.String()).
+				Msg("failed to set user timeline")
+		}
+	}
+}
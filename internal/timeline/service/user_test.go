@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+func TestTimelineService_UsersPosts(t *testing.T) {
+	ctx := context.TODO()
+
+	author1, author2, author3 := xid.New(), xid.New(), xid.New()
+	post1 := entity.Post{PostID: xid.New(), AuthorID: author1}
+	post2 := entity.Post{PostID: xid.New(), AuthorID: author2}
+	post3 := entity.Post{PostID: xid.New(), AuthorID: author1}
+	post4 := entity.Post{PostID: xid.New(), AuthorID: author1}
+
+	ts := newTestService(t,
+		fakeRelationService{},
+		fakePostService{posts: []entity.Post{post4, post3, post2, post1}})
+	ts.cfg.UserTimelineLimit = 2
+
+	// the result is limited for all authors together: only the author
+	// with the full list of recent posts in it is saved
+	posts, err := ts.usersPosts(ctx, []xid.ID{author1, author2, author3}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post4.PostID, post3.PostID}, postIDs(posts))
+
+	cached, err := ts.repo.UserListGet(ctx, []xid.ID{author1, author2, author3}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, map[xid.ID][]entity.Post{author1: {post4, post3}}, cached)
+
+	// the complete result is saved for each author
+	posts, err = ts.usersPosts(ctx, []xid.ID{author2, author3}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []xid.ID{post2.PostID}, postIDs(posts))
+
+	cached, err = ts.repo.UserListGet(ctx, []xid.ID{author1, author2, author3}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, map[xid.ID][]entity.Post{
+		author1: {post4, post3},
+		author2: {post2},
+		author3: nil,
+	}, cached)
+}
+
+func TestTimelineService_UsersPosts_LimitedLists(t *testing.T) {
+	ctx := context.TODO()
+
+	author1, author2 := xid.New(), xid.New()
+	posts := []entity.Post{
+		{PostID: xid.NewWithTime(time.Now().Add(-1 * time.Minute)), AuthorID: author1},
+		{PostID: xid.NewWithTime(time.Now().Add(-2 * time.Minute)), AuthorID: author1},
+		{PostID: xid.NewWithTime(time.Now().Add(-3 * time.Minute)), AuthorID: author2},
+		{PostID: xid.NewWithTime(time.Now().Add(-4 * time.Minute)), AuthorID: author1},
+		{PostID: xid.NewWithTime(time.Now().Add(-5 * time.Minute)), AuthorID: author2},
+	}
+
+	ts := newTestService(t, fakeRelationService{}, fakePostService{posts: posts})
+	ts.cfg.UserTimelineLimit = 2
+
+	// the posts requested for all authors together are complete
+	res, err := ts.usersPosts(ctx, []xid.ID{author1, author2}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, postIDs(posts), postIDs(res))
+
+	// the cached lists miss the oldest post of the first author,
+	// so the older posts of the second author are not returned
+	res, err = ts.usersPosts(ctx, []xid.ID{author1, author2}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, postIDs(posts[:2]), postIDs(res))
+}
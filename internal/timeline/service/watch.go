@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Karzoug/meower-common-go/ucerr"
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+
+	tlbroker "github.com/Karzoug/meower-timeline-service/internal/timeline/broker"
+	"github.com/Karzoug/meower-timeline-service/internal/timeline/entity"
+)
+
+// TimelineWatch is a subscription to the user timeline changes.
+type TimelineWatch struct {
+	sub *tlbroker.Subscription
+}
+
+// Events returns channel of the timeline changes,
+// it's closed when the watch is aborted.
+func (w TimelineWatch) Events() <-chan entity.TimelineEvent {
+	return w.sub.Events()
+}
+
+// Err returns the reason why the watch was aborted,
+// it must be called only after the events channel is closed.
+func (w TimelineWatch) Err() error {
+	err := w.sub.Err()
+	if errors.Is(err, tlbroker.ErrSlowConsumer) {
+		return ucerr.NewError(err, "timeline changes are not consumed in time", codes.ResourceExhausted)
+	}
+	return err
+}
+
+// Close closes the watch.
+func (w TimelineWatch) Close() {
+	w.sub.Close()
+}
+
+func (ts TimelineService) WatchTimeline(ctx context.Context, reqUserID, userID xid.ID) (TimelineWatch, error) {
+	if reqUserID.Compare(userID) != 0 {
+		return TimelineWatch{}, ucerr.NewError(nil, "user id mismatch", codes.PermissionDenied)
+	}
+
+	sub, err := ts.broker.Subscribe(ctx, userID)
+	if err != nil {
+		if errors.Is(err, tlbroker.ErrTooManySubscriptions) {
+			return TimelineWatch{}, ucerr.NewError(err, "too many timeline watchers", codes.ResourceExhausted)
+		}
+		return TimelineWatch{}, ucerr.NewInternalError(err)
+	}
+
+	return TimelineWatch{sub: sub}, nil
+}
+
+// publish notifies the user timeline watchers about the change,
+// the change is already applied, so the error is only logged.
+func (ts TimelineService) publish(ctx context.Context, userID xid.ID, eventType entity.EventType, post entity.Post) {
+	if err := ts.broker.Publish(ctx, userID, entity.TimelineEvent{
+		Type: eventType,
+		Post: post,
+	}); err != nil {
+		ts.logger.Warn().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("failed to publish timeline event")
+	}
+}
@@ -20,7 +20,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TimelineService_ListTimeline_FullMethodName = "/timeline.v1.TimelineService/ListTimeline"
+	TimelineService_ListTimeline_FullMethodName         = "/timeline.v1.TimelineService/ListTimeline"
+	TimelineService_ListNewTimelinePosts_FullMethodName = "/timeline.v1.TimelineService/ListNewTimelinePosts"
+	TimelineService_WatchTimeline_FullMethodName        = "/timeline.v1.TimelineService/WatchTimeline"
 )
 
 // TimelineServiceClient is the client API for TimelineService service.
@@ -35,6 +37,12 @@ const (
 // in the context metadata (key: x-user-id).
 type TimelineServiceClient interface {
 	ListTimeline(ctx context.Context, in *ListTimelineRequest, opts ...grpc.CallOption) (*ListTimelineResponse, error)
+	// Returns posts of the timeline newer than the given one or only their count.
+	ListNewTimelinePosts(ctx context.Context, in *ListNewTimelinePostsRequest, opts ...grpc.CallOption) (*ListNewTimelinePostsResponse, error)
+	// Streams changes of the timeline as soon as they are applied.
+	// The stream is aborted if the client doesn't keep up with changes,
+	// in that case the client should re-read the timeline.
+	WatchTimeline(ctx context.Context, in *WatchTimelineRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchTimelineResponse], error)
 }
 
 type timelineServiceClient struct {
@@ -55,6 +63,35 @@ func (c *timelineServiceClient) ListTimeline(ctx context.Context, in *ListTimeli
 	return out, nil
 }
 
+func (c *timelineServiceClient) ListNewTimelinePosts(ctx context.Context, in *ListNewTimelinePostsRequest, opts ...grpc.CallOption) (*ListNewTimelinePostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNewTimelinePostsResponse)
+	err := c.cc.Invoke(ctx, TimelineService_ListNewTimelinePosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timelineServiceClient) WatchTimeline(ctx context.Context, in *WatchTimelineRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchTimelineResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TimelineService_ServiceDesc.Streams[0], TimelineService_WatchTimeline_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTimelineRequest, WatchTimelineResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TimelineService_WatchTimelineClient = grpc.ServerStreamingClient[WatchTimelineResponse]
+
 // TimelineServiceServer is the server API for TimelineService service.
 // All implementations must embed UnimplementedTimelineServiceServer
 // for forward compatibility.
@@ -67,6 +104,12 @@ func (c *timelineServiceClient) ListTimeline(ctx context.Context, in *ListTimeli
 // in the context metadata (key: x-user-id).
 type TimelineServiceServer interface {
 	ListTimeline(context.Context, *ListTimelineRequest) (*ListTimelineResponse, error)
+	// Returns posts of the timeline newer than the given one or only their count.
+	ListNewTimelinePosts(context.Context, *ListNewTimelinePostsRequest) (*ListNewTimelinePostsResponse, error)
+	// Streams changes of the timeline as soon as they are applied.
+	// The stream is aborted if the client doesn't keep up with changes,
+	// in that case the client should re-read the timeline.
+	WatchTimeline(*WatchTimelineRequest, grpc.ServerStreamingServer[WatchTimelineResponse]) error
 	mustEmbedUnimplementedTimelineServiceServer()
 }
 
@@ -80,6 +123,12 @@ type UnimplementedTimelineServiceServer struct{}
 func (UnimplementedTimelineServiceServer) ListTimeline(context.Context, *ListTimelineRequest) (*ListTimelineResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListTimeline not implemented")
 }
+func (UnimplementedTimelineServiceServer) ListNewTimelinePosts(context.Context, *ListNewTimelinePostsRequest) (*ListNewTimelinePostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNewTimelinePosts not implemented")
+}
+func (UnimplementedTimelineServiceServer) WatchTimeline(*WatchTimelineRequest, grpc.ServerStreamingServer[WatchTimelineResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTimeline not implemented")
+}
 func (UnimplementedTimelineServiceServer) mustEmbedUnimplementedTimelineServiceServer() {}
 func (UnimplementedTimelineServiceServer) testEmbeddedByValue()                         {}
 
@@ -119,6 +168,35 @@ func _TimelineService_ListTimeline_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TimelineService_ListNewTimelinePosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNewTimelinePostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimelineServiceServer).ListNewTimelinePosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimelineService_ListNewTimelinePosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimelineServiceServer).ListNewTimelinePosts(ctx, req.(*ListNewTimelinePostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimelineService_WatchTimeline_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTimelineRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TimelineServiceServer).WatchTimeline(m, &grpc.GenericServerStream[WatchTimelineRequest, WatchTimelineResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TimelineService_WatchTimelineServer = grpc.ServerStreamingServer[WatchTimelineResponse]
+
 // TimelineService_ServiceDesc is the grpc.ServiceDesc for TimelineService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -130,7 +208,17 @@ var TimelineService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListTimeline",
 			Handler:    _TimelineService_ListTimeline_Handler,
 		},
+		{
+			MethodName: "ListNewTimelinePosts",
+			Handler:    _TimelineService_ListNewTimelinePosts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTimeline",
+			Handler:       _TimelineService_WatchTimeline_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "timeline/v1/grpc.proto",
 }
@@ -30,6 +30,12 @@ const (
 	ChangeTaskType_CHANGE_TASK_TYPE_USER_DELETE      ChangeTaskType = 3
 	ChangeTaskType_CHANGE_TASK_TYPE_USER_SUBSCRIBE   ChangeTaskType = 5
 	ChangeTaskType_CHANGE_TASK_TYPE_USER_UNSUBSCRIBE ChangeTaskType = 6
+	// user_id muted target_user_id
+	ChangeTaskType_CHANGE_TASK_TYPE_USER_MUTE ChangeTaskType = 7
+	// user_id unmuted target_user_id
+	ChangeTaskType_CHANGE_TASK_TYPE_USER_UNMUTE ChangeTaskType = 8
+	// reposter_id reposted post_id of user_id, it should be shown in target_user_id timeline
+	ChangeTaskType_CHANGE_TASK_TYPE_REPOST_INSERT ChangeTaskType = 9
 )
 
 // Enum value maps for ChangeTaskType.
@@ -41,6 +47,9 @@ var (
 		3: "CHANGE_TASK_TYPE_USER_DELETE",
 		5: "CHANGE_TASK_TYPE_USER_SUBSCRIBE",
 		6: "CHANGE_TASK_TYPE_USER_UNSUBSCRIBE",
+		7: "CHANGE_TASK_TYPE_USER_MUTE",
+		8: "CHANGE_TASK_TYPE_USER_UNMUTE",
+		9: "CHANGE_TASK_TYPE_REPOST_INSERT",
 	}
 	ChangeTaskType_value = map[string]int32{
 		"CHANGE_TASK_TYPE_UNSPECIFIED":      0,
@@ -49,6 +58,9 @@ var (
 		"CHANGE_TASK_TYPE_USER_DELETE":      3,
 		"CHANGE_TASK_TYPE_USER_SUBSCRIBE":   5,
 		"CHANGE_TASK_TYPE_USER_UNSUBSCRIBE": 6,
+		"CHANGE_TASK_TYPE_USER_MUTE":        7,
+		"CHANGE_TASK_TYPE_USER_UNMUTE":      8,
+		"CHANGE_TASK_TYPE_REPOST_INSERT":    9,
 	}
 )
 
@@ -88,6 +100,11 @@ type ChangeTaskEvent struct {
 	PostId       string         `protobuf:"bytes,2,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
 	UserId       string         `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ChangeType   ChangeTaskType `protobuf:"varint,4,opt,name=change_type,json=changeType,proto3,enum=timeline.v1.ChangeTaskType" json:"change_type,omitempty"`
+	// The user who reposted the post (only for repost tasks).
+	ReposterId string `protobuf:"bytes,5,opt,name=reposter_id,json=reposterId,proto3" json:"reposter_id,omitempty"`
+	// The repost id ordering the repost in timelines (only for repost tasks),
+	// if empty, the repost is ordered by the task time.
+	RepostId string `protobuf:"bytes,6,opt,name=repost_id,json=repostId,proto3" json:"repost_id,omitempty"`
 }
 
 func (x *ChangeTaskEvent) Reset() {
@@ -148,12 +165,26 @@ func (x *ChangeTaskEvent) GetChangeType() ChangeTaskType {
 	return ChangeTaskType_CHANGE_TASK_TYPE_UNSPECIFIED
 }
 
+func (x *ChangeTaskEvent) GetReposterId() string {
+	if x != nil {
+		return x.ReposterId
+	}
+	return ""
+}
+
+func (x *ChangeTaskEvent) GetRepostId() string {
+	if x != nil {
+		return x.RepostId
+	}
+	return ""
+}
+
 var File_timeline_v1_kafka_proto protoreflect.FileDescriptor
 
 var file_timeline_v1_kafka_proto_rawDesc = []byte{
 	0x0a, 0x17, 0x74, 0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x6b, 0x61,
 	0x66, 0x6b, 0x61, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x6c,
-	0x69, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xa7, 0x01, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x69, 0x6e, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xe5, 0x01, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x67,
 	0x65, 0x54, 0x61, 0x73, 0x6b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x61,
 	0x72, 0x67, 0x65, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0c, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64,
@@ -164,22 +195,33 @@ var file_timeline_v1_kafka_proto_rawDesc = []byte{
 	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x74, 0x69, 0x6d, 0x65, 0x6c, 0x69,
 	0x6e, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x61, 0x73, 0x6b,
 	0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65,
-	0x2a, 0xe4, 0x01, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x54,
-	0x79, 0x70, 0x65, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41,
-	0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
-	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f,
-	0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4f, 0x53, 0x54, 0x5f, 0x49,
-	0x4e, 0x53, 0x45, 0x52, 0x54, 0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47,
-	0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4f, 0x53, 0x54,
-	0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41,
+	0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x2a, 0xca,
+	0x02, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41,
+	0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4f, 0x53, 0x54, 0x5f, 0x49, 0x4e, 0x53,
+	0x45, 0x52, 0x54, 0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f,
+	0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x4f, 0x53, 0x54, 0x5f, 0x44,
+	0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47,
+	0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x53, 0x45, 0x52,
+	0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x03, 0x12, 0x23, 0x0a, 0x1f, 0x43, 0x48, 0x41,
 	0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x53,
-	0x45, 0x52, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x03, 0x12, 0x23, 0x0a, 0x1f, 0x43,
-	0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
-	0x55, 0x53, 0x45, 0x52, 0x5f, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52, 0x49, 0x42, 0x45, 0x10, 0x05,
-	0x12, 0x25, 0x0a, 0x21, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f,
-	0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x55, 0x42, 0x53,
-	0x43, 0x52, 0x49, 0x42, 0x45, 0x10, 0x06, 0x42, 0x0d, 0x5a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x6c,
-	0x69, 0x6e, 0x65, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x45, 0x52, 0x5f, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52, 0x49, 0x42, 0x45, 0x10, 0x05, 0x12, 0x25,
+	0x0a, 0x21, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52,
+	0x49, 0x42, 0x45, 0x10, 0x06, 0x12, 0x1e, 0x0a, 0x1a, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f,
+	0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x4d,
+	0x55, 0x54, 0x45, 0x10, 0x07, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f,
+	0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x5f, 0x55,
+	0x4e, 0x4d, 0x55, 0x54, 0x45, 0x10, 0x08, 0x12, 0x22, 0x0a, 0x1e, 0x43, 0x48, 0x41, 0x4e, 0x47,
+	0x45, 0x5f, 0x54, 0x41, 0x53, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x50, 0x4f,
+	0x53, 0x54, 0x5f, 0x49, 0x4e, 0x53, 0x45, 0x52, 0x54, 0x10, 0x09, 0x42, 0x0d, 0x5a, 0x0b, 0x74,
+	0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
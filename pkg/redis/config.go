@@ -1,6 +1,82 @@
 package redis
 
+import "time"
+
+// ReadRouting is the policy of routing reads that tolerate replication lag.
+type ReadRouting string
+
+const (
+	// ReadRoutingPrimary serves all reads by primary nodes.
+	ReadRoutingPrimary ReadRouting = "primary"
+	// ReadRoutingReplica serves reads by replica nodes of the cluster slot or by sentinel replicas.
+	ReadRoutingReplica ReadRouting = "replica"
+	// ReadRoutingLatency serves reads by the closest node of the cluster slot (primary or replica).
+	ReadRoutingLatency ReadRouting = "latency"
+	// ReadRoutingRandom serves reads by a random node of the cluster slot (primary or replica).
+	ReadRoutingRandom ReadRouting = "random"
+)
+
+// Config is the configuration of redis client: single node if one address is given,
+// cluster if several and sentinel failover if master name is set.
+// Zero values of the pool and timeout options mean the go-redis defaults.
 type Config struct {
-	Addrs         []string `env:"ADDRS,notEmpty" envSeparator:","`
-	EnableTracing bool     `env:"ENABLE_TRACING" envDefault:"false"`
+	Addrs []string `env:"ADDRS,notEmpty" envSeparator:","`
+	// Username and Password are the ACL credentials of redis nodes.
+	Username string `env:"USERNAME"`
+	Password string `env:"PASSWORD,unset"`
+	// DB is the database index, single node and sentinel only.
+	DB int `env:"DB" envDefault:"0"`
+	// MasterName is the sentinel master name, Addrs are the sentinel addresses then.
+	MasterName       string `env:"MASTER_NAME"`
+	SentinelUsername string `env:"SENTINEL_USERNAME"`
+	SentinelPassword string `env:"SENTINEL_PASSWORD,unset"`
+	// ReadRouting is the policy of DB.Replica client, the main client always uses primary nodes.
+	// Sentinel supports only replica routing, single node ignores it.
+	// If empty, the policy is taken from the deprecated options below, primary if none is set.
+	ReadRouting ReadRouting `env:"READ_ROUTING"`
+	// Deprecated: use ReadRouting, ReadOnly means replica routing,
+	// RouteByLatency and RouteRandomly mean latency and random routing.
+	ReadOnly       bool `env:"READ_ONLY" envDefault:"false"`
+	RouteByLatency bool `env:"ROUTE_BY_LATENCY" envDefault:"false"`
+	RouteRandomly  bool `env:"ROUTE_RANDOMLY" envDefault:"false"`
+
+	PoolSize     int           `env:"POOL_SIZE"`
+	MinIdleConns int           `env:"MIN_IDLE_CONNS"`
+	PoolTimeout  time.Duration `env:"POOL_TIMEOUT"`
+	DialTimeout  time.Duration `env:"DIAL_TIMEOUT"`
+	ReadTimeout  time.Duration `env:"READ_TIMEOUT"`
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT"`
+	MaxRetries   int           `env:"MAX_RETRIES"`
+
+	TLS TLSConfig `envPrefix:"TLS_"`
+
+	EnableTracing bool `env:"ENABLE_TRACING" envDefault:"false"`
+	EnableMetrics bool `env:"ENABLE_METRICS" envDefault:"false"`
+}
+
+// readRouting returns the read routing policy taking into account the deprecated options.
+func (cfg Config) readRouting() ReadRouting {
+	switch {
+	case cfg.ReadRouting != "":
+		return cfg.ReadRouting
+	case cfg.RouteByLatency:
+		return ReadRoutingLatency
+	case cfg.RouteRandomly:
+		return ReadRoutingRandom
+	case cfg.ReadOnly:
+		return ReadRoutingReplica
+	default:
+		return ReadRoutingPrimary
+	}
+}
+
+type TLSConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"false"`
+	// CAFile is the PEM file of the CA certificates to verify the server, system pool if empty.
+	CAFile string `env:"CA_FILE"`
+	// CertFile and KeyFile are the PEM files of the client certificate for mutual TLS.
+	CertFile           string `env:"CERT_FILE"`
+	KeyFile            string `env:"KEY_FILE"`
+	ServerName         string `env:"SERVER_NAME"`
+	InsecureSkipVerify bool   `env:"INSECURE_SKIP_VERIFY" envDefault:"false"`
 }
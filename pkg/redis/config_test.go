@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Env(t *testing.T) {
+	var cfg Config
+	require.NoError(t, env.ParseWithOptions(&cfg, env.Options{
+		Prefix: "REDIS_",
+		Environment: map[string]string{
+			"REDIS_ADDRS":             "redis-1:6379,redis-2:6379",
+			"REDIS_USERNAME":          "timeline",
+			"REDIS_PASSWORD":          "secret",
+			"REDIS_DB":                "2",
+			"REDIS_MASTER_NAME":       "main",
+			"REDIS_POOL_SIZE":         "32",
+			"REDIS_DIAL_TIMEOUT":      "2s",
+			"REDIS_READ_TIMEOUT":      "500ms",
+			"REDIS_TLS_ENABLED":       "true",
+			"REDIS_TLS_SERVER_NAME":   "redis",
+			"REDIS_ENABLE_METRICS":    "true",
+			"REDIS_SENTINEL_PASSWORD": "sentinel",
+		},
+	}))
+
+	assert.Equal(t, Config{
+		Addrs:            []string{"redis-1:6379", "redis-2:6379"},
+		Username:         "timeline",
+		Password:         "secret",
+		DB:               2,
+		MasterName:       "main",
+		SentinelPassword: "sentinel",
+		PoolSize:         32,
+		DialTimeout:      2 * time.Second,
+		ReadTimeout:      500 * time.Millisecond,
+		TLS:              TLSConfig{Enabled: true, ServerName: "redis"},
+		EnableMetrics:    true,
+	}, cfg)
+}
+
+func TestConfig_readRouting(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want ReadRouting
+	}{
+		{name: "default", cfg: Config{}, want: ReadRoutingPrimary},
+		{name: "explicit", cfg: Config{ReadRouting: ReadRoutingReplica}, want: ReadRoutingReplica},
+		{name: "explicit wins over deprecated", cfg: Config{ReadRouting: ReadRoutingPrimary, ReadOnly: true, RouteByLatency: true}, want: ReadRoutingPrimary},
+		{name: "read only", cfg: Config{ReadOnly: true}, want: ReadRoutingReplica},
+		{name: "route by latency", cfg: Config{ReadOnly: true, RouteByLatency: true}, want: ReadRoutingLatency},
+		{name: "route randomly", cfg: Config{ReadOnly: true, RouteRandomly: true}, want: ReadRoutingRandom},
+		{name: "latency wins over random", cfg: Config{RouteByLatency: true, RouteRandomly: true}, want: ReadRoutingLatency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.readRouting())
+		})
+	}
+}
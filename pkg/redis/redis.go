@@ -2,6 +2,8 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
@@ -9,26 +11,123 @@ import (
 
 type DB struct {
 	redis.UniversalClient
+	replica redis.UniversalClient // nil if reads are not routed to replicas
 }
 
 func NewDB(ctx context.Context, cfg Config) (DB, error) {
-	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: cfg.Addrs,
-	})
+	tlsCfg, err := cfg.TLS.tlsConfig()
+	if err != nil {
+		return DB{}, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		MasterName:       cfg.MasterName,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolTimeout:      cfg.PoolTimeout,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		MaxRetries:       cfg.MaxRetries,
+		TLSConfig:        tlsCfg,
+	}
+
+	replica, err := newReplicaClient(opts, cfg.readRouting())
+	if err != nil {
+		return DB{}, err
+	}
+
+	db := DB{
+		UniversalClient: redis.NewUniversalClient(opts),
+		replica:         replica,
+	}
+
+	for _, rdb := range db.clients() {
+		if err := setupClient(ctx, rdb, cfg); err != nil {
+			return DB{}, errors.Join(err, db.Close(ctx))
+		}
+	}
+
+	return db, nil
+}
+
+// newReplicaClient returns the client routing read-only commands according to the policy
+// or nil if reads are served by primary nodes.
+func newReplicaClient(opts *redis.UniversalOptions, routing ReadRouting) (redis.UniversalClient, error) {
+	switch routing {
+	case "", ReadRoutingPrimary:
+		return nil, nil
+	case ReadRoutingReplica, ReadRoutingLatency, ReadRoutingRandom:
+	default:
+		return nil, fmt.Errorf("unknown read routing: %q", routing)
+	}
 
+	switch {
+	case opts.MasterName != "":
+		if routing != ReadRoutingReplica {
+			return nil, fmt.Errorf("read routing %q is not supported by sentinel", routing)
+		}
+		failoverOpts := opts.Failover()
+		failoverOpts.ReplicaOnly = true
+		return redis.NewFailoverClient(failoverOpts), nil
+	case len(opts.Addrs) > 1:
+		clusterOpts := opts.Cluster()
+		clusterOpts.ReadOnly = true
+		clusterOpts.RouteByLatency = routing == ReadRoutingLatency
+		clusterOpts.RouteRandomly = routing == ReadRoutingRandom
+		return redis.NewClusterClient(clusterOpts), nil
+	default:
+		// single node has no known replicas
+		return nil, nil
+	}
+}
+
+func setupClient(ctx context.Context, rdb redis.UniversalClient, cfg Config) error {
 	if cmd := rdb.Ping(ctx); cmd.Err() != nil {
-		return DB{}, cmd.Err()
+		return cmd.Err()
 	}
 
 	if cfg.EnableTracing {
 		if err := redisotel.InstrumentTracing(rdb); err != nil {
-			return DB{}, err
+			return err
+		}
+	}
+	if cfg.EnableMetrics {
+		if err := redisotel.InstrumentMetrics(rdb); err != nil {
+			return err
 		}
 	}
 
-	return DB{UniversalClient: rdb}, nil
+	return nil
+}
+
+// Replica returns the client for reads that tolerate replication lag:
+// it routes them according to the read routing policy. Reads of own writes
+// and scripts must use the main client.
+func (db DB) Replica() redis.UniversalClient {
+	if db.replica == nil {
+		return db.UniversalClient
+	}
+	return db.replica
+}
+
+func (db DB) clients() []redis.UniversalClient {
+	if db.replica == nil {
+		return []redis.UniversalClient{db.UniversalClient}
+	}
+	return []redis.UniversalClient{db.UniversalClient, db.replica}
 }
 
 func (db DB) Close(_ context.Context) error {
-	return db.UniversalClient.Close()
+	var errs []error
+	for _, rdb := range db.clients() {
+		errs = append(errs, rdb.Close())
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newReplicaClient(t *testing.T) {
+	single := &redis.UniversalOptions{Addrs: []string{"localhost:6379"}}
+	cluster := &redis.UniversalOptions{Addrs: []string{"localhost:7000", "localhost:7001"}}
+	sentinel := &redis.UniversalOptions{Addrs: []string{"localhost:26379"}, MasterName: "main"}
+
+	t.Run("primary", func(t *testing.T) {
+		for _, routing := range []ReadRouting{"", ReadRoutingPrimary} {
+			rdb, err := newReplicaClient(cluster, routing)
+			require.NoError(t, err)
+			assert.Nil(t, rdb)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := newReplicaClient(cluster, "nearest")
+		assert.ErrorContains(t, err, "unknown read routing")
+	})
+
+	t.Run("single node", func(t *testing.T) {
+		rdb, err := newReplicaClient(single, ReadRoutingReplica)
+		require.NoError(t, err)
+		assert.Nil(t, rdb)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		tests := []struct {
+			routing        ReadRouting
+			routeByLatency bool
+			routeRandomly  bool
+		}{
+			{routing: ReadRoutingReplica},
+			{routing: ReadRoutingLatency, routeByLatency: true},
+			{routing: ReadRoutingRandom, routeRandomly: true},
+		}
+		for _, tt := range tests {
+			rdb, err := newReplicaClient(cluster, tt.routing)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = rdb.Close() })
+
+			require.IsType(t, &redis.ClusterClient{}, rdb)
+			opts := rdb.(*redis.ClusterClient).Options()
+			assert.True(t, opts.ReadOnly, tt.routing)
+			assert.Equal(t, tt.routeByLatency, opts.RouteByLatency, tt.routing)
+			assert.Equal(t, tt.routeRandomly, opts.RouteRandomly, tt.routing)
+		}
+	})
+
+	t.Run("sentinel", func(t *testing.T) {
+		rdb, err := newReplicaClient(sentinel, ReadRoutingReplica)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = rdb.Close() })
+		assert.IsType(t, &redis.Client{}, rdb)
+
+		for _, routing := range []ReadRouting{ReadRoutingLatency, ReadRoutingRandom} {
+			_, err := newReplicaClient(sentinel, routing)
+			assert.ErrorContains(t, err, "not supported by sentinel", routing)
+		}
+	})
+}
+
+func TestDB_Replica(t *testing.T) {
+	main := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	replica := redis.NewClient(&redis.Options{Addr: "localhost:6380"})
+
+	db := DB{UniversalClient: main}
+	assert.Same(t, main, db.Replica(), "falls back to the main client")
+	assert.Len(t, db.clients(), 1)
+	assert.NoError(t, db.Close(context.Background()))
+
+	db = DB{UniversalClient: redis.NewClient(&redis.Options{Addr: "localhost:6379"}), replica: replica}
+	assert.Same(t, replica, db.Replica())
+	assert.Len(t, db.clients(), 2)
+	assert.NoError(t, db.Close(context.Background()))
+}
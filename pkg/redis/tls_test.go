@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes the self-signed certificate and its key in PEM files to dir.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestTLSConfig_tlsConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+	emptyFile := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0o600))
+
+	tlsCfg, err := TLSConfig{}.tlsConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsCfg, "disabled TLS")
+
+	tlsCfg, err = TLSConfig{
+		Enabled:    true,
+		CAFile:     certFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "redis",
+	}.tlsConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsCfg.MinVersion)
+	assert.Equal(t, "redis", tlsCfg.ServerName)
+	assert.False(t, tlsCfg.InsecureSkipVerify)
+	assert.NotNil(t, tlsCfg.RootCAs)
+	assert.Len(t, tlsCfg.Certificates, 1)
+
+	// system pool and no client certificate
+	tlsCfg, err = TLSConfig{Enabled: true}.tlsConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsCfg.RootCAs)
+	assert.Empty(t, tlsCfg.Certificates)
+
+	tests := []struct {
+		name string
+		cfg  TLSConfig
+		err  string
+	}{
+		{name: "missing CA file", cfg: TLSConfig{Enabled: true, CAFile: filepath.Join(dir, "missing.pem")}, err: "failed to read CA file"},
+		{name: "empty CA file", cfg: TLSConfig{Enabled: true, CAFile: emptyFile}, err: "no certificates in CA file"},
+		{name: "certificate without key", cfg: TLSConfig{Enabled: true, CertFile: certFile}, err: "failed to load client certificate"},
+		{name: "key without certificate", cfg: TLSConfig{Enabled: true, KeyFile: keyFile}, err: "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cfg.tlsConfig()
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}